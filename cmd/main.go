@@ -4,15 +4,30 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	framework "github.com/tbe/resource-framework/resource"
 	"github.com/vmware/concourse-vrealize-automation-resource/pkg/resource"
 )
 
 func main() {
+	// Cancel the context when Concourse aborts the build
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
 	// Create new vRA Resource
 	res := &resource.VRAResource{
+		Ctx:       ctx,
 		Src:       &resource.VRASource{},
 		Ver:       &resource.VRAVersion{},
 		OutParams: &resource.OutParams{},
@@ -0,0 +1,176 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package csp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
+// APIError is returned for requests which got unexpected
+// status code, and can be tested for with errors.As
+type APIError = httpUtils.APIError
+
+// Authenticator generates access tokens for
+// vRealize Automation APIs
+type Authenticator interface {
+	Authenticate(ctx context.Context, httpClient *httpUtils.Client) (Token, error)
+}
+
+// Token holds the access token and its lifetime.
+// ExpiresIn is zero when lifetime is not known.
+type Token struct {
+	AccessToken string
+	ExpiresIn   time.Duration
+}
+
+// RefreshTokenAuthenticator exchanges the API/refresh
+// token for an access token
+type RefreshTokenAuthenticator struct {
+	APIBaseURL   string `json:"apiBaseURL"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// NewRefreshTokenAuthenticator creates authenticator pointer for given refresh token
+func NewRefreshTokenAuthenticator(apiBaseURL string, refreshToken string) *RefreshTokenAuthenticator {
+	return &RefreshTokenAuthenticator{APIBaseURL: apiBaseURL, RefreshToken: refreshToken}
+}
+
+type accessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Authenticate generates CSP access token
+func (authenticator *RefreshTokenAuthenticator) Authenticate(ctx context.Context, httpClient *httpUtils.Client) (Token, error) {
+	// Construct headers
+	headers := make(map[string]string)
+	headers["Content-Type"] = "application/x-www-form-urlencoded"
+
+	// Construct Form data
+	formData := make(map[string]string)
+	formData["refresh_token"] = authenticator.RefreshToken
+
+	// Fire the request
+	response, err := httpClient.PostHeadersFormData(ctx, authenticator.APIBaseURL+cspAccessTokenURL, formData, headers)
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return Token{}, fmt.Errorf("Error while getting the CSP access token. Error : %w", err)
+	}
+
+	// Unmarshall the access token response
+	var accessTokenResponse accessTokenResponse
+	err = json.Unmarshal([]byte(response.ResponseString), &accessTokenResponse)
+	if err != nil {
+		return Token{}, fmt.Errorf("Error while unmarshalling the CSP access token. Error : %w", err)
+	}
+	return Token{
+		AccessToken: accessTokenResponse.AccessToken,
+		ExpiresIn:   time.Duration(accessTokenResponse.ExpiresIn) * time.Second,
+	}, nil
+}
+
+// PasswordAuthenticator logs in to the identity service with
+// username and password to get a refresh token, and exchanges
+// it for a bearer token using vRealize Automation login API
+type PasswordAuthenticator struct {
+	APIBaseURL    string `json:"apiBaseURL"`
+	VRAAPIBaseURL string `json:"vraAPIBaseURL"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	Domain        string `json:"domain"`
+}
+
+// NewPasswordAuthenticator creates authenticator pointer for given credentials
+func NewPasswordAuthenticator(apiBaseURL string, vraAPIBaseURL string, username string, password string, domain string) *PasswordAuthenticator {
+	return &PasswordAuthenticator{
+		APIBaseURL:    apiBaseURL,
+		VRAAPIBaseURL: vraAPIBaseURL,
+		Username:      username,
+		Password:      password,
+		Domain:        domain,
+	}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Domain   string `json:"domain,omitempty"`
+}
+
+type loginResponse struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type iaasLoginRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type iaasLoginResponse struct {
+	TokenType string `json:"tokenType"`
+	Token     string `json:"token"`
+}
+
+// Authenticate generates bearer token for given credentials.
+// vRealize Automation login API does not return the token
+// lifetime, so it is left unknown.
+func (authenticator *PasswordAuthenticator) Authenticate(ctx context.Context, httpClient *httpUtils.Client) (Token, error) {
+	refreshToken, err := authenticator.getRefreshToken(ctx, httpClient)
+	if err != nil {
+		return Token{}, err
+	}
+
+	// Marshal request struct to JSON
+	requestBodyJSONBytes, err := json.Marshal(iaasLoginRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return Token{}, err
+	}
+
+	// Fire the request
+	headers := map[string]string{"Content-Type": "application/json"}
+	response, err := httpClient.PostHeaders(ctx, authenticator.VRAAPIBaseURL+iaasLoginURL, string(requestBodyJSONBytes), headers)
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return Token{}, fmt.Errorf("Error while getting the vRealize Automation access token. Error : %w", err)
+	}
+
+	// Unmarshall the login response
+	var loginResponse iaasLoginResponse
+	err = json.Unmarshal([]byte(response.ResponseString), &loginResponse)
+	if err != nil {
+		return Token{}, fmt.Errorf("Error while unmarshalling the vRealize Automation access token. Error : %w", err)
+	}
+	return Token{AccessToken: loginResponse.Token}, nil
+}
+
+// getRefreshToken logs in to the identity service and returns the refresh token
+func (authenticator *PasswordAuthenticator) getRefreshToken(ctx context.Context, httpClient *httpUtils.Client) (string, error) {
+	// Marshal request struct to JSON
+	requestBodyJSONBytes, err := json.Marshal(loginRequest{
+		Username: authenticator.Username,
+		Password: authenticator.Password,
+		Domain:   authenticator.Domain,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Fire the request
+	headers := map[string]string{"Content-Type": "application/json"}
+	response, err := httpClient.PostHeaders(ctx, authenticator.APIBaseURL+cspLoginURL, string(requestBodyJSONBytes), headers)
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return "", fmt.Errorf("Error while logging in to vRealize Automation. Error : %w", err)
+	}
+
+	// Unmarshall the login response
+	var loginResponse loginResponse
+	err = json.Unmarshal([]byte(response.ResponseString), &loginResponse)
+	if err != nil {
+		return "", fmt.Errorf("Error while unmarshalling the login response. Error : %w", err)
+	}
+	return loginResponse.RefreshToken, nil
+}
@@ -4,60 +4,116 @@
 package csp
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
 )
 
 const (
-	cspAPIBaseURL     = "https://console.cloud.vmware.com/csp/gateway/am/api"
-	cspAccessTokenURL = cspAPIBaseURL + "/auth/api-tokens/authorize"
+	// CloudAPIBaseURL is the CSP API base URL of VMware Cloud
+	CloudAPIBaseURL = "https://console.cloud.vmware.com" + APIPath
+	// APIPath is the CSP API path, relative to the identity
+	// service host. On-prem vRealize Automation serves it
+	// from the appliance itself.
+	APIPath           = "/csp/gateway/am/api"
+	cspAccessTokenURL = "/auth/api-tokens/authorize"
+	cspLoginURL       = "/login?access_token"
+	iaasLoginURL      = "/iaas/api/login"
+
+	// Access token is refreshed this much before it expires
+	tokenRefreshMargin = time.Minute
+	// Lifetime assumed for access tokens without expiry info
+	defaultTokenLifetime = time.Minute * 25
 )
 
-// Client provides all util methods for given refresh token
+// Client provides all util methods for given authenticator.
+// It caches the access token until shortly before it expires
+// and is safe for concurrent use.
 type Client struct {
-	RefreshToken string `json:"refreshToken"`
-}
+	HTTPClient    *httpUtils.Client
+	Authenticator Authenticator
 
-// New cretes client pointer for all CSP related utils
-func New(refreshToken string) *Client {
-	return &Client{RefreshToken: refreshToken}
+	mutex       sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+	refresh     *tokenRefresh
 }
 
-type accessTokenResponse struct {
-	AccessToken string `json:"access_token"`
+// tokenRefresh holds the result of an access token refresh,
+// available once done is closed
+type tokenRefresh struct {
+	done        chan struct{}
+	accessToken string
+	err         error
 }
 
-// GetAccessToken generates CSP access token
-func (cspClient *Client) GetAccessToken() (string, error) {
-	// Construct headers
-	headers := make(map[string]string)
-	headers["Content-Type"] = "application/x-www-form-urlencoded"
+// New cretes client pointer for all CSP related utils
+func New(httpClient *httpUtils.Client, authenticator Authenticator) *Client {
+	return &Client{HTTPClient: httpClient, Authenticator: authenticator}
+}
 
-	// Construct Form data
-	formData := make(map[string]string)
-	formData["refresh_token"] = cspClient.RefreshToken
+// GetAccessToken returns the cached access token, or generates
+// a new one using the authenticator if it is about to expire.
+// Concurrent callers share a single refresh, which is made
+// without holding the lock.
+func (cspClient *Client) GetAccessToken(ctx context.Context) (string, error) {
+	cspClient.mutex.Lock()
+	if cspClient.accessToken != "" && time.Now().Add(tokenRefreshMargin).Before(cspClient.expiresAt) {
+		accessToken := cspClient.accessToken
+		cspClient.mutex.Unlock()
+		return accessToken, nil
+	}
 
-	// Fire the request
-	response, err := httpUtils.PostHeadersFormDataRetry(cspAccessTokenURL, formData, headers)
-	if err != nil || response.Code != 200 {
-		return "", fmt.Errorf("Error while getting the CSP access token : %s. Error : %w", response.Message, err)
+	// Wait for the refresh in progress
+	if refresh := cspClient.refresh; refresh != nil {
+		cspClient.mutex.Unlock()
+		select {
+		case <-refresh.done:
+			return refresh.accessToken, refresh.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
 	}
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	cspClient.refresh = refresh
+	cspClient.mutex.Unlock()
 
-	// Unmarshall the access token response
-	var accessTokenResponse accessTokenResponse
-	err = json.Unmarshal([]byte(response.ResponseString), &accessTokenResponse)
-	if err != nil {
-		return "", fmt.Errorf("Error while unmarshalling the CSP access token. Error : %w", err)
+	token, err := cspClient.Authenticator.Authenticate(ctx, cspClient.HTTPClient)
+
+	cspClient.mutex.Lock()
+	defer cspClient.mutex.Unlock()
+	if err == nil {
+		expiresIn := token.ExpiresIn
+		if expiresIn <= 0 {
+			expiresIn = defaultTokenLifetime
+		}
+		cspClient.accessToken = token.AccessToken
+		cspClient.expiresAt = time.Now().Add(expiresIn)
+		refresh.accessToken = token.AccessToken
 	}
-	return accessTokenResponse.AccessToken, err
+	refresh.err = err
+	cspClient.refresh = nil
+	close(refresh.done)
+	return refresh.accessToken, err
+}
+
+// InvalidateAccessToken drops the cached access token, so that
+// next call generates a new one
+func (cspClient *Client) InvalidateAccessToken() {
+	cspClient.mutex.Lock()
+	defer cspClient.mutex.Unlock()
+
+	cspClient.accessToken = ""
+	cspClient.expiresAt = time.Time{}
 }
 
 // GetAuthHeaders constructs Authorization header map with CSP access token
-func (cspClient *Client) GetAuthHeaders() (map[string]string, error) {
+func (cspClient *Client) GetAuthHeaders(ctx context.Context) (map[string]string, error) {
 	// Get CSP access token
-	accessToken, err := cspClient.GetAccessToken()
+	accessToken, err := cspClient.GetAccessToken(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,132 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package csp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
+// countingAuthenticator returns a new token per call,
+// after release is closed when provided
+type countingAuthenticator struct {
+	calls     int32
+	expiresIn time.Duration
+	err       error
+	release   chan struct{}
+}
+
+func (authenticator *countingAuthenticator) Authenticate(ctx context.Context, httpClient *httpUtils.Client) (Token, error) {
+	calls := atomic.AddInt32(&authenticator.calls, 1)
+	if authenticator.release != nil {
+		<-authenticator.release
+	}
+	if authenticator.err != nil {
+		return Token{}, authenticator.err
+	}
+	return Token{AccessToken: fmt.Sprintf("token-%d", calls), ExpiresIn: authenticator.expiresIn}, nil
+}
+
+func TestGetAccessTokenIsCached(t *testing.T) {
+	authenticator := &countingAuthenticator{}
+	cspClient := New(nil, authenticator)
+
+	for i := 0; i < 3; i++ {
+		token, err := cspClient.GetAccessToken(context.Background())
+		if err != nil || token != "token-1" {
+			t.Fatalf("GetAccessToken() = %q, %v, want token-1", token, err)
+		}
+	}
+
+	cspClient.InvalidateAccessToken()
+	token, err := cspClient.GetAccessToken(context.Background())
+	if err != nil || token != "token-2" {
+		t.Errorf("GetAccessToken() after invalidating = %q, %v, want token-2", token, err)
+	}
+}
+
+func TestGetAccessTokenRefreshesExpiring(t *testing.T) {
+	// Tokens expiring within the refresh margin are never reused
+	authenticator := &countingAuthenticator{expiresIn: tokenRefreshMargin / 2}
+	cspClient := New(nil, authenticator)
+
+	for i := 1; i <= 3; i++ {
+		token, err := cspClient.GetAccessToken(context.Background())
+		if want := fmt.Sprintf("token-%d", i); err != nil || token != want {
+			t.Fatalf("GetAccessToken() = %q, %v, want %s", token, err, want)
+		}
+	}
+}
+
+func TestGetAccessTokenSharesRefresh(t *testing.T) {
+	authenticator := &countingAuthenticator{release: make(chan struct{})}
+	cspClient := New(nil, authenticator)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	tokens := make([]string, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = cspClient.GetAccessToken(context.Background())
+		}(i)
+	}
+
+	// Let all callers reach the refresh before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(authenticator.release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&authenticator.calls); calls != 1 {
+		t.Errorf("got %d authentications, want 1", calls)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil || tokens[i] != "token-1" {
+			t.Errorf("caller %d got %q, %v, want token-1", i, tokens[i], errs[i])
+		}
+	}
+}
+
+func TestGetAccessTokenSharesRefreshError(t *testing.T) {
+	authErr := errors.New("invalid refresh token")
+	authenticator := &countingAuthenticator{err: authErr}
+	cspClient := New(nil, authenticator)
+
+	if _, err := cspClient.GetAccessToken(context.Background()); err != authErr {
+		t.Fatalf("GetAccessToken() error = %v, want %v", err, authErr)
+	}
+
+	// Failed refresh is not cached
+	authenticator.err = nil
+	token, err := cspClient.GetAccessToken(context.Background())
+	if err != nil || token != "token-2" {
+		t.Errorf("GetAccessToken() after failure = %q, %v, want token-2", token, err)
+	}
+}
+
+func TestGetAccessTokenWaitIsCanceled(t *testing.T) {
+	authenticator := &countingAuthenticator{release: make(chan struct{})}
+	defer close(authenticator.release)
+	cspClient := New(nil, authenticator)
+
+	go cspClient.GetAccessToken(context.Background())
+	for atomic.LoadInt32(&authenticator.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cspClient.GetAccessToken(ctx); err != context.DeadlineExceeded {
+		t.Errorf("GetAccessToken() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
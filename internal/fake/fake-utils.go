@@ -0,0 +1,92 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fake provides fakes of the vRealize Automation APIs for tests
+package fake
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/csp"
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
+// APIBaseURL is the API base URL to create clients with
+const APIBaseURL = "https://vra.example.com"
+
+// Authenticator returns a fixed access token
+type Authenticator struct{}
+
+// Authenticate returns a fixed access token
+func (Authenticator) Authenticate(ctx context.Context, httpClient *httpUtils.Client) (csp.Token, error) {
+	return csp.Token{AccessToken: "token"}, nil
+}
+
+// Request holds the details of a request sent to Transport
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   string
+}
+
+// Transport records the requests and answers them with Handle
+type Transport struct {
+	Handle func(request Request) (int, string)
+
+	mutex    sync.Mutex
+	requests []Request
+}
+
+// RoundTrip records the request and answers it with Handle
+func (transport *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request := Request{Method: req.Method, Path: req.URL.Path, Query: req.URL.Query()}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		request.Body = string(body)
+	}
+	transport.mutex.Lock()
+	transport.requests = append(transport.requests, request)
+	transport.mutex.Unlock()
+
+	statusCode, body := transport.Handle(request)
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// Requests returns the requests recorded so far
+func (transport *Transport) Requests() []Request {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return append([]Request(nil), transport.requests...)
+}
+
+// NewHTTPClient creates HTTP client sending its requests
+// to given transport, without retries
+func NewHTTPClient(t *testing.T, transport http.RoundTripper) *httpUtils.Client {
+	t.Helper()
+	httpClient, err := httpUtils.NewClient(httpUtils.ClientOptions{Transport: transport, RetryCount: -1})
+	if err != nil {
+		t.Fatalf("Error while creating HTTP client: %v", err)
+	}
+	return httpClient
+}
+
+// NewCSPClient creates CSP client authenticating with Authenticator
+func NewCSPClient(httpClient *httpUtils.Client) *csp.Client {
+	return csp.New(httpClient, Authenticator{})
+}
@@ -4,33 +4,59 @@
 package vra
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vmware/concourse-vrealize-automation-resource/internal/csp"
 	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
 )
 
 const (
-	vraAPIBaseURL          = "https://api.mgmt.cloud.vmware.com"
 	pipelineExecutionURL   = "/codestream/api/executions/%s"
+	cancelExecutionURL     = pipelineExecutionURL + "/cancel"
 	pipelineExecutionModel = "/codestream/api/pipelines/%s/executions"
 	pipelineIDURIPath      = "/codestream/api/pipelines"
-	getExecutionURL        = vraAPIBaseURL + "/codestream/api/executions/%s?expand=PIPELINE_STAGE_TASK"
+	projectURL             = "/project-service/api/projects/%s"
+	getExecutionURL        = "/codestream/api/executions/%s?expand=PIPELINE_STAGE_TASK"
+
+	// ExecutionsPageSize is the number of executions fetched per page
+	ExecutionsPageSize = 100
+
+	// Number of times execute request is fired again
+	// after proving that no execution was created
+	executeRetryCount = 2
+	// Allowed difference between local and server clocks
+	// while looking for the created execution
+	executionClockSkew = time.Minute
 )
 
+// APIError is returned for requests which got unexpected
+// status code, and can be tested for with errors.As
+type APIError = httpUtils.APIError
+
+// projectIDPattern matches project IDs, which are UUIDs
+var projectIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 // Client provides all util methods for
 // the given CSP client
 type Client struct {
-	CspClient *csp.Client
+	HTTPClient *httpUtils.Client
+	APIBaseURL string
+	CspClient  *csp.Client
 }
 
 // New creates Code Stream client pointer
-func New(cspClient *csp.Client) *Client {
-	return &Client{CspClient: cspClient}
+func New(httpClient *httpUtils.Client, apiBaseURL string, cspClient *csp.Client) *Client {
+	return &Client{HTTPClient: httpClient, APIBaseURL: apiBaseURL, CspClient: cspClient}
 }
 
 type Links struct {
@@ -39,8 +65,8 @@ type Links struct {
 
 // PipelineExecutionReq holds execute request body
 type PipelineExecutionReq struct {
-	Comments string            `json:"comments"`
-	Input    map[string]string `json:"input"`
+	Comments string                 `json:"comments"`
+	Input    map[string]interface{} `json:"input"`
 }
 
 // PipelineExecutionResp holds execution response body
@@ -50,51 +76,89 @@ type PipelineExecutionResp struct {
 	ExecutionIndex int    `json:"executionIndex"`
 }
 
+// CancelExecutionReq holds cancel request body
+type CancelExecutionReq struct {
+	Comments string `json:"comments"`
+}
+
 // PipelineExecution holds pipeline execution record
 type PipelineExecution struct {
 	ID            string                            `json:"id"`
 	Index         int                               `json:"index"`
 	Project       string                            `json:"project"`
-	Status        string                            `json:"status"`
+	Status        ExecutionStatus                   `json:"status"`
 	StatusMessage string                            `json:"statusMessage"`
 	Comments      string                            `json:"comments"`
+	Input         map[string]interface{}            `json:"input"`
 	Output        map[string]string                 `json:"output"`
 	StageOrder    []string                          `json:"stageOrder"`
 	Stages        map[string]PipelineStageExecution `json:"stages"`
+
+	RequestTimeInMicros int64 `json:"_requestTimeInMicros"`
+}
+
+// PipelineExecutions holds the list of pipeline execution records
+type PipelineExecutions struct {
+	Count      int                          `json:"count"`
+	TotalCount int                          `json:"totalCount"`
+	Documents  map[string]PipelineExecution `json:"documents"`
 }
 
 // PipelineStageExecution holds pipeline stage execution record
 type PipelineStageExecution struct {
-	Status    string                           `json:"status"`
-	TaskOrder []string                         `json:"taskOrder"`
-	Tasks     map[string]PipelineTaskExecution `json:"tasks"`
+	Status        ExecutionStatus                  `json:"status"`
+	StatusMessage string                           `json:"statusMessage"`
+	TaskOrder     []string                         `json:"taskOrder"`
+	Tasks         map[string]PipelineTaskExecution `json:"tasks"`
 }
 
 // PipelineTaskExecution holds pipeline task execution record
 type PipelineTaskExecution struct {
-	Status string                 `json:"status"`
-	Type   string                 `json:"type"`
-	Output map[string]interface{} `json:"output"`
+	Status        ExecutionStatus        `json:"status"`
+	StatusMessage string                 `json:"statusMessage"`
+	Type          string                 `json:"type"`
+	Output        map[string]interface{} `json:"output"`
 }
 
 // ExecutePipeline executes the pipeline with given request body
-func (csClient *Client) ExecutePipeline(pipelineID string, execReq PipelineExecutionReq) (PipelineExecutionResp, error) {
-	headers, err := getHeaders(csClient)
-	if err != nil {
-		return PipelineExecutionResp{}, err
-	}
-
+func (csClient *Client) ExecutePipeline(ctx context.Context, pipelineID string, execReq PipelineExecutionReq) (PipelineExecutionResp, error) {
 	// Marshal request struct to JSON
 	requestBodyJSONBytes, err := json.Marshal(execReq)
 	if err != nil {
 		return PipelineExecutionResp{}, err
 	}
 
-	// Fire the request
-	executePipelineURL := fmt.Sprintf(vraAPIBaseURL+pipelineExecutionModel, pipelineID)
-	response, err := httpUtils.PostHeadersRetry(executePipelineURL, string(requestBodyJSONBytes), headers)
-	if err != nil || response.Code != 202 {
-		return PipelineExecutionResp{}, fmt.Errorf("Error while executing pipeline: %s. %w", response.Message, err)
+	// Fire the request. Executing is not idempotent, so when it is not
+	// known whether the execution got created, it is fired again only
+	// if no matching execution is found among the recent ones.
+	executePipelineURL := fmt.Sprintf(csClient.APIBaseURL+pipelineExecutionModel, pipelineID)
+	var response httpUtils.Response
+	for attempt := 0; ; attempt++ {
+		requestTime := time.Now()
+		response, err = csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+			return csClient.HTTPClient.PostHeaders(ctx, executePipelineURL, string(requestBodyJSONBytes), headers)
+		})
+		if attempt >= executeRetryCount || !isOutcomeUnknown(ctx, response, err) {
+			break
+		}
+
+		execution, found, findErr := csClient.findExecution(ctx, pipelineID, execReq, requestTime)
+		if findErr != nil {
+			// The execution may exist, so the operator has to check for it
+			executeErr := httpUtils.CheckResponse(response, err, http.StatusAccepted)
+			return PipelineExecutionResp{}, fmt.Errorf("Error while executing pipeline: outcome unknown and recent executions could not be checked, "+
+				"so the execution may already exist. Check for a duplicate execution before retrying. Executing failed with: %w. Checking failed with: %v", executeErr, findErr)
+		}
+		if found {
+			return PipelineExecutionResp{
+				ExecutionID:    execution.ID,
+				ExecutionLink:  fmt.Sprintf(pipelineExecutionURL, execution.ID),
+				ExecutionIndex: execution.Index,
+			}, nil
+		}
+	}
+	if err := httpUtils.CheckResponse(response, err, http.StatusAccepted); err != nil {
+		return PipelineExecutionResp{}, fmt.Errorf("Error while executing pipeline: %w", err)
 	}
 
 	// Parse execution response
@@ -106,19 +170,73 @@ func (csClient *Client) ExecutePipeline(pipelineID string, execReq PipelineExecu
 	return executionResponse, nil
 }
 
-// GetPipelineExecution fetches pipeline execution record for given
-// executionID
-func (csClient *Client) GetPipelineExecution(executionID string) (PipelineExecution, error) {
-	headers, err := getHeaders(csClient)
+// isOutcomeUnknown tells whether the request might have created
+// the execution, though no successful response was received
+func isOutcomeUnknown(ctx context.Context, response httpUtils.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !httpUtils.IsNotSent(err)
+	}
+	return response.Code >= 500
+}
+
+// findExecution looks for the execution created for the given request
+// among the recent executions of the pipeline
+func (csClient *Client) findExecution(ctx context.Context, pipelineID string, execReq PipelineExecutionReq, requestTime time.Time) (PipelineExecution, bool, error) {
+	executions, err := csClient.GetPipelineExecutions(ctx, pipelineID)
+	if err != nil {
+		return PipelineExecution{}, false, err
+	}
+
+	notBefore := requestTime.Add(-executionClockSkew).UnixNano() / int64(time.Microsecond)
+	for _, execution := range executions {
+		if execution.RequestTimeInMicros >= notBefore && isSameRequest(execution, execReq) {
+			return execution, true, nil
+		}
+	}
+	return PipelineExecution{}, false, nil
+}
+
+// isSameRequest tells whether the execution has the comments and
+// input of the request. Execution input also holds the defaults of
+// inputs which are not provided, hence only provided ones are compared.
+func isSameRequest(execution PipelineExecution, execReq PipelineExecutionReq) bool {
+	if execution.Comments != execReq.Comments {
+		return false
+	}
+	for key, value := range execReq.Input {
+		if !isSameValue(execution.Input[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSameValue compares the values by their JSON
+func isSameValue(value interface{}, other interface{}) bool {
+	valueJSONBytes, err := json.Marshal(value)
 	if err != nil {
-		return PipelineExecution{}, err
+		return false
 	}
+	otherJSONBytes, err := json.Marshal(other)
+	if err != nil {
+		return false
+	}
+	return string(valueJSONBytes) == string(otherJSONBytes)
+}
 
+// GetPipelineExecution fetches pipeline execution record for given
+// executionID
+func (csClient *Client) GetPipelineExecution(ctx context.Context, executionID string) (PipelineExecution, error) {
 	// Fire the request
-	getExecutionURL := fmt.Sprintf(getExecutionURL, executionID)
-	response, err := httpUtils.GetHeadersRetry(getExecutionURL, headers)
-	if err != nil || response.Code != 200 {
-		return PipelineExecution{}, fmt.Errorf("Error while getting pipeline execution details: %s. %w", response.Message, err)
+	getExecutionURL := fmt.Sprintf(csClient.APIBaseURL+getExecutionURL, executionID)
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, getExecutionURL, headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return PipelineExecution{}, fmt.Errorf("Error while getting pipeline execution details: %w", err)
 	}
 
 	// Parse the pipeline execution
@@ -130,51 +248,190 @@ func (csClient *Client) GetPipelineExecution(executionID string) (PipelineExecut
 	return pipelineExecution, nil
 }
 
-// GetPipelineIDFromName returns pipline ID of the given pipeline name
-func (csClient *Client) GetPipelineIDFromName(pipelineName string) (string, error) {
+// CancelExecution cancels the pipeline execution for given
+// executionID, recording the reason in its comments
+func (csClient *Client) CancelExecution(ctx context.Context, executionID string, reason string) error {
+	// Marshal request struct to JSON
+	requestBodyJSONBytes, err := json.Marshal(CancelExecutionReq{Comments: reason})
+	if err != nil {
+		return err
+	}
+
+	// Fire the request
+	cancelExecutionURL := fmt.Sprintf(csClient.APIBaseURL+cancelExecutionURL, executionID)
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.PostHeaders(ctx, cancelExecutionURL, string(requestBodyJSONBytes), headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return fmt.Errorf("Error while canceling pipeline execution: %w", err)
+	}
+	return nil
+}
+
+// GetPipelineExecutions fetches the latest execution records of the given
+// pipeline, ordered by execution index in ascending order
+func (csClient *Client) GetPipelineExecutions(ctx context.Context, pipelineID string) ([]PipelineExecution, error) {
+	return csClient.GetPipelineExecutionsPage(ctx, pipelineID, 0)
+}
+
+// GetPipelineExecutionsPage fetches up to ExecutionsPageSize execution
+// records of the given pipeline, skipping the given number of latest
+// ones. Records are ordered by execution index in ascending order.
+func (csClient *Client) GetPipelineExecutionsPage(ctx context.Context, pipelineID string, skip int) ([]PipelineExecution, error) {
 	// Construct API URL with query param encoding
-	baseURL, _ := url.Parse(vraAPIBaseURL)
-	baseURL.Path += pipelineIDURIPath
+	baseURL, err := url.Parse(csClient.APIBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	baseURL.Path += fmt.Sprintf(pipelineExecutionModel, pipelineID)
 	params := url.Values{}
-	pipilineNameAdd := fmt.Sprintf("name eq '%s'", pipelineName)
-	params.Add("$filter", pipilineNameAdd)
+	params.Add("$orderby", "_requestTimeInMicros desc")
+	params.Add("$top", strconv.Itoa(ExecutionsPageSize))
+	if skip > 0 {
+		params.Add("$skip", strconv.Itoa(skip))
+	}
 	baseURL.RawQuery = params.Encode()
-	searchPipelinesURL := baseURL.String()
+	listExecutionsURL := baseURL.String()
+
+	// Fire the request
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, listExecutionsURL, headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("Error while getting pipeline executions: %w", err)
+	}
+
+	// Parse the pipeline executions
+	var pipelineExecutions PipelineExecutions
+	err = json.Unmarshal([]byte(response.ResponseString), &pipelineExecutions)
+	if err != nil {
+		return nil, fmt.Errorf("Error while unmarshalling the pipeline executions response : %s. %v", response.Message, err)
+	}
+
+	executions := make([]PipelineExecution, 0, len(pipelineExecutions.Documents))
+	for _, execution := range pipelineExecutions.Documents {
+		executions = append(executions, execution)
+	}
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].Index < executions[j].Index
+	})
+	return executions, nil
+}
 
-	// Construct headers
-	headers, err := getHeaders(csClient)
+// GetPipelineIDFromName returns pipline ID of the given pipeline name.
+// When project name or ID is given, the lookup is scoped to that project
+// as pipeline names are only unique per project.
+func (csClient *Client) GetPipelineIDFromName(ctx context.Context, pipelineName string, project string) (string, error) {
+	// Resolve project name as pipelines are filtered by project name
+	projectName, err := csClient.getProjectName(ctx, project)
 	if err != nil {
 		return "", err
 	}
 
-	// Fire
-	resp, err := httpUtils.GetHeadersRetry(searchPipelinesURL, headers)
+	// Construct API URL with query param encoding
+	baseURL, err := url.Parse(csClient.APIBaseURL)
 	if err != nil {
 		return "", err
 	}
+	baseURL.Path += pipelineIDURIPath
+	params := url.Values{}
+	pipilineNameAdd := fmt.Sprintf("name eq '%s'", escapeFilterValue(pipelineName))
+	if projectName != "" {
+		pipilineNameAdd += fmt.Sprintf(" and project eq '%s'", escapeFilterValue(projectName))
+	}
+	params.Add("$filter", pipilineNameAdd)
+	baseURL.RawQuery = params.Encode()
+	searchPipelinesURL := baseURL.String()
+
+	// Fire
+	resp, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, searchPipelinesURL, headers)
+	})
+	if err := httpUtils.CheckResponse(resp, err, http.StatusOK); err != nil {
+		return "", fmt.Errorf("Error while searching pipelines: %w", err)
+	}
 
 	// Parse Links
 	var links Links
 	err = json.Unmarshal([]byte(resp.ResponseString), &links)
 	if err != nil {
-		fmt.Println(err)
+		return "", fmt.Errorf("Error while unmarshalling the pipelines response : %s. %v", resp.Message, err)
 	}
 	var linksExtracted []string
 	for _, link := range links.Links {
-		linksExtracted = append(linksExtracted, strings.SplitAfter(link, "/codestream/api/pipelines/")[1])
+		linksExtracted = append(linksExtracted, strings.SplitAfter(link, pipelineIDURIPath+"/")[1])
 	}
 
 	if len(linksExtracted) < 1 {
-		return "", nil
+		if projectName != "" {
+			return "", fmt.Errorf("No pipeline named '%s' found in project '%s'", pipelineName, projectName)
+		}
+		return "", fmt.Errorf("No pipeline named '%s' found", pipelineName)
 	} else if len(linksExtracted) > 1 {
-		return "", errors.New("More than 1 matching pipeline found for given name")
+		return "", errors.New("More than 1 matching pipeline found for given name. Provide project to narrow down the search")
 	}
 
 	return linksExtracted[0], nil
 }
 
-func getHeaders(csClient *Client) (map[string]string, error) {
-	headers, err := csClient.CspClient.GetAuthHeaders()
+// Project holds project record
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// getProjectName returns the name of given project name or ID
+func (csClient *Client) getProjectName(ctx context.Context, project string) (string, error) {
+	if !projectIDPattern.MatchString(project) {
+		return project, nil
+	}
+
+	// Fire the request
+	getProjectURL := fmt.Sprintf(csClient.APIBaseURL+projectURL, project)
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, getProjectURL, headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return "", fmt.Errorf("Error while getting project %s: %w", project, err)
+	}
+
+	// Parse the project
+	var projectRecord Project
+	err = json.Unmarshal([]byte(response.ResponseString), &projectRecord)
+	if err != nil {
+		return "", fmt.Errorf("Error while unmarshalling the project response : %s. %v", response.Message, err)
+	}
+	return projectRecord.Name, nil
+}
+
+// escapeFilterValue escapes single quotes in OData filter string literals
+func escapeFilterValue(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// fireWithAuth fires the request with auth headers. If the access
+// token is rejected, it retries once with a new access token.
+func (csClient *Client) fireWithAuth(ctx context.Context, fire func(headers map[string]string) (httpUtils.Response, error)) (httpUtils.Response, error) {
+	headers, err := getHeaders(ctx, csClient)
+	if err != nil {
+		return httpUtils.Response{}, err
+	}
+	response, err := fire(headers)
+	if err != nil || response.Code != http.StatusUnauthorized {
+		return response, err
+	}
+
+	// Retry with a new access token
+	csClient.CspClient.InvalidateAccessToken()
+	headers, err = getHeaders(ctx, csClient)
+	if err != nil {
+		return httpUtils.Response{}, err
+	}
+	return fire(headers)
+}
+
+func getHeaders(ctx context.Context, csClient *Client) (map[string]string, error) {
+	headers, err := csClient.CspClient.GetAuthHeaders(ctx)
 	if err != nil {
 		return nil, err
 	}
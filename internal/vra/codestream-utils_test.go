@@ -0,0 +1,241 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
+func TestIsSameValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		other interface{}
+		want  bool
+	}{
+		{"same strings", "a", "a", true},
+		{"different strings", "a", "b", false},
+		{"string and number", "1", float64(1), false},
+		{"same numbers", float64(1), 1, true},
+		{"lists of different types", []interface{}{"a", true}, []string{"a", "true"}, false},
+		{"same objects", map[string]interface{}{"a": 1, "b": "c"}, map[string]interface{}{"b": "c", "a": 1}, true},
+		{"both null", nil, nil, true},
+		{"nil and empty string", nil, "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isSameValue(test.value, test.other); got != test.want {
+				t.Errorf("isSameValue(%v, %v) = %v, want %v", test.value, test.other, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsSameRequest(t *testing.T) {
+	execution := PipelineExecution{
+		Comments: "build 42",
+		Input:    map[string]interface{}{"env": "prod", "replicas": float64(2), "debug": ""},
+	}
+	tests := []struct {
+		name    string
+		execReq PipelineExecutionReq
+		want    bool
+	}{
+		{"same", PipelineExecutionReq{Comments: "build 42", Input: map[string]interface{}{"env": "prod", "replicas": 2}}, true},
+		{"defaults not provided", PipelineExecutionReq{Comments: "build 42"}, true},
+		{"different comments", PipelineExecutionReq{Comments: "build 43", Input: map[string]interface{}{"env": "prod"}}, false},
+		{"different input", PipelineExecutionReq{Comments: "build 42", Input: map[string]interface{}{"env": "dev"}}, false},
+		{"input not in execution", PipelineExecutionReq{Comments: "build 42", Input: map[string]interface{}{"region": "eu"}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isSameRequest(execution, test.execReq); got != test.want {
+				t.Errorf("isSameRequest() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsOutcomeUnknown(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		response httpUtils.Response
+		err      error
+		want     bool
+	}{
+		{"accepted", context.Background(), httpUtils.Response{Code: http.StatusAccepted}, nil, false},
+		{"rejected", context.Background(), httpUtils.Response{Code: http.StatusBadRequest}, nil, false},
+		{"rate limited", context.Background(), httpUtils.Response{Code: http.StatusTooManyRequests}, nil, false},
+		{"server error", context.Background(), httpUtils.Response{Code: http.StatusInternalServerError}, nil, true},
+		{"gateway timeout", context.Background(), httpUtils.Response{Code: http.StatusGatewayTimeout}, nil, true},
+		{"not sent", context.Background(), httpUtils.Response{}, dialErr, false},
+		{"reset after sent", context.Background(), httpUtils.Response{}, readErr, true},
+		{"context done", canceled, httpUtils.Response{Code: http.StatusInternalServerError}, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isOutcomeUnknown(test.ctx, test.response, test.err); got != test.want {
+				t.Errorf("isOutcomeUnknown() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// executeServer fakes the execute and list executions APIs.
+// Executions are listed only once they are created.
+type executeServer struct {
+	mutex          sync.Mutex
+	executeCodes   []int
+	listCode       int
+	executeCalls   int
+	listCalls      int
+	createdOnError bool
+	executions     map[string]PipelineExecution
+}
+
+func (server *executeServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	writer.Header().Set("Content-Type", "application/json")
+	switch request.Method {
+	case http.MethodPost:
+		var execReq PipelineExecutionReq
+		if err := json.NewDecoder(request.Body).Decode(&execReq); err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		code := server.executeCodes[server.executeCalls]
+		server.executeCalls++
+		if code == http.StatusAccepted || server.createdOnError {
+			id := fmt.Sprintf("e%d", len(server.executions)+1)
+			server.executions[id] = PipelineExecution{ID: id, Index: len(server.executions) + 1, Comments: execReq.Comments,
+				Input: execReq.Input, RequestTimeInMicros: time.Now().UnixNano() / int64(time.Microsecond)}
+			if code == http.StatusAccepted {
+				writer.WriteHeader(code)
+				fmt.Fprintf(writer, `{"executionId":"%s","executionIndex":%d}`, id, len(server.executions))
+				return
+			}
+		}
+		writer.WriteHeader(code)
+		fmt.Fprint(writer, `{"message":"internal error"}`)
+	case http.MethodGet:
+		server.listCalls++
+		if server.listCode != 0 {
+			writer.WriteHeader(server.listCode)
+			fmt.Fprint(writer, `{"message":"list failed"}`)
+			return
+		}
+		json.NewEncoder(writer).Encode(PipelineExecutions{Count: len(server.executions), Documents: server.executions})
+	}
+}
+
+func newExecuteClient(t *testing.T, server *executeServer) *Client {
+	t.Helper()
+	server.executions = map[string]PipelineExecution{}
+	testServer := httptest.NewServer(server)
+	t.Cleanup(testServer.Close)
+
+	httpClient, err := httpUtils.NewClient(httpUtils.ClientOptions{RetryCount: -1})
+	if err != nil {
+		t.Fatalf("Error while creating HTTP client: %v", err)
+	}
+	return New(httpClient, testServer.URL, fake.NewCSPClient(httpClient))
+}
+
+func TestExecutePipeline(t *testing.T) {
+	execReq := PipelineExecutionReq{Comments: "build 42", Input: map[string]interface{}{"env": "prod"}}
+	tests := []struct {
+		name             string
+		server           *executeServer
+		wantID           string
+		wantExecuteCalls int
+		wantListCalls    int
+		wantErr          string
+	}{
+		{
+			name:             "accepted",
+			server:           &executeServer{executeCodes: []int{http.StatusAccepted}},
+			wantID:           "e1",
+			wantExecuteCalls: 1,
+		},
+		{
+			name:             "created despite error is found",
+			server:           &executeServer{executeCodes: []int{http.StatusBadGateway}, createdOnError: true},
+			wantID:           "e1",
+			wantExecuteCalls: 1,
+			wantListCalls:    1,
+		},
+		{
+			name:             "not created is fired again",
+			server:           &executeServer{executeCodes: []int{http.StatusBadGateway, http.StatusAccepted}},
+			wantID:           "e1",
+			wantExecuteCalls: 2,
+			wantListCalls:    1,
+		},
+		{
+			name:             "gives up after retries",
+			server:           &executeServer{executeCodes: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}},
+			wantExecuteCalls: executeRetryCount + 1,
+			wantListCalls:    executeRetryCount,
+			wantErr:          "502",
+		},
+		{
+			name:             "rejected is not fired again",
+			server:           &executeServer{executeCodes: []int{http.StatusBadRequest}},
+			wantExecuteCalls: 1,
+			wantErr:          "400",
+		},
+		{
+			name:             "lookup failure is reported",
+			server:           &executeServer{executeCodes: []int{http.StatusBadGateway}, listCode: http.StatusServiceUnavailable},
+			wantExecuteCalls: 1,
+			wantListCalls:    1,
+			wantErr:          "recent executions could not be checked",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := test.server
+			csClient := newExecuteClient(t, server)
+
+			resp, err := csClient.ExecutePipeline(context.Background(), "p1", execReq)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("ExecutePipeline() error = %v, want it to contain %q", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ExecutePipeline() error = %v", err)
+			}
+			if resp.ExecutionID != test.wantID {
+				t.Errorf("ExecutePipeline() execution = %q, want %q", resp.ExecutionID, test.wantID)
+			}
+			if server.executeCalls != test.wantExecuteCalls {
+				t.Errorf("got %d execute calls, want %d", server.executeCalls, test.wantExecuteCalls)
+			}
+			if server.listCalls != test.wantListCalls {
+				t.Errorf("got %d list calls, want %d", server.listCalls, test.wantListCalls)
+			}
+		})
+	}
+}
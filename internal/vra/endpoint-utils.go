@@ -0,0 +1,55 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/csp"
+)
+
+const (
+	cloudAPIBaseURL = "https://api.mgmt.cloud.vmware.com"
+	cloudDomain     = "mgmt.cloud.vmware.com"
+)
+
+// Endpoints holds the API base URLs of
+// a vRealize Automation instance
+type Endpoints struct {
+	VRAAPIBaseURL string
+	CSPAPIBaseURL string
+}
+
+// ResolveEndpoints works out the API base URLs from the configured host.
+// An empty host or any VMware Cloud host resolves to the VMware Cloud
+// endpoints. Any other host is treated as an on-prem vRealize Automation
+// appliance, which serves both the APIs and the identity service.
+func ResolveEndpoints(host string) (Endpoints, error) {
+	if strings.TrimSpace(host) == "" {
+		return Endpoints{VRAAPIBaseURL: cloudAPIBaseURL, CSPAPIBaseURL: csp.CloudAPIBaseURL}, nil
+	}
+
+	// Default to HTTPS when scheme is not provided
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return Endpoints{}, fmt.Errorf("Invalid host %s. Error : %w", host, err)
+	}
+	if hostURL.Hostname() == "" {
+		return Endpoints{}, fmt.Errorf("Invalid host %s", host)
+	}
+
+	hostname := strings.ToLower(hostURL.Hostname())
+	if hostname == cloudDomain || strings.HasSuffix(hostname, "."+cloudDomain) {
+		return Endpoints{VRAAPIBaseURL: cloudAPIBaseURL, CSPAPIBaseURL: csp.CloudAPIBaseURL}, nil
+	}
+
+	// On-prem, ignore any path such as /codestream
+	baseURL := hostURL.Scheme + "://" + hostURL.Host
+	return Endpoints{VRAAPIBaseURL: baseURL, CSPAPIBaseURL: baseURL + csp.APIPath}, nil
+}
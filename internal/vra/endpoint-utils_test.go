@@ -0,0 +1,47 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/csp"
+)
+
+func TestResolveEndpoints(t *testing.T) {
+	cloud := Endpoints{VRAAPIBaseURL: "https://api.mgmt.cloud.vmware.com", CSPAPIBaseURL: csp.CloudAPIBaseURL}
+	tests := []struct {
+		name    string
+		host    string
+		want    Endpoints
+		wantErr bool
+	}{
+		{"empty host", "", cloud, false},
+		{"blank host", "  ", cloud, false},
+		{"cloud API host", "api.mgmt.cloud.vmware.com", cloud, false},
+		{"cloud host with scheme and path", "https://www.mgmt.cloud.vmware.com/codestream", cloud, false},
+		{"cloud domain", "mgmt.cloud.vmware.com", cloud, false},
+		{"on-prem host", "vra.example.com",
+			Endpoints{VRAAPIBaseURL: "https://vra.example.com", CSPAPIBaseURL: "https://vra.example.com" + csp.APIPath}, false},
+		{"on-prem host with port and path", "https://vra.example.com:8443/codestream",
+			Endpoints{VRAAPIBaseURL: "https://vra.example.com:8443", CSPAPIBaseURL: "https://vra.example.com:8443" + csp.APIPath}, false},
+		{"on-prem host with http", "http://vra.local",
+			Endpoints{VRAAPIBaseURL: "http://vra.local", CSPAPIBaseURL: "http://vra.local" + csp.APIPath}, false},
+		{"lookalike of cloud domain", "evilmgmt.cloud.vmware.com",
+			Endpoints{VRAAPIBaseURL: "https://evilmgmt.cloud.vmware.com", CSPAPIBaseURL: "https://evilmgmt.cloud.vmware.com" + csp.APIPath}, false},
+		{"invalid host", "https://", Endpoints{}, true},
+		{"invalid URL", "https://vra example.com:port", Endpoints{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ResolveEndpoints(test.host)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ResolveEndpoints(%q) error = %v, wantErr %v", test.host, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ResolveEndpoints(%q) = %+v, want %+v", test.host, got, test.want)
+			}
+		})
+	}
+}
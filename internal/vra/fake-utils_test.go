@@ -0,0 +1,19 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+)
+
+// newFakeClient creates Code Stream client sending its requests
+// to given transport, without retries
+func newFakeClient(t *testing.T, transport http.RoundTripper) *Client {
+	t.Helper()
+	httpClient := fake.NewHTTPClient(t, transport)
+	return New(httpClient, fake.APIBaseURL, fake.NewCSPClient(httpClient))
+}
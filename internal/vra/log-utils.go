@@ -0,0 +1,103 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
+const (
+	taskLogsURL = "/codestream/api/executions/%s/stages/%s/tasks/%s/logs"
+	ciTaskType  = "CI"
+
+	// MaxTaskLogBytes caps the logs of a single task. The
+	// end of the logs is kept, as it explains the failure.
+	MaxTaskLogBytes = 64 * 1024
+)
+
+// taskLogOutputFields holds the task output fields carrying
+// logs, e.g. SSH and PowerShell script output or REST responses
+var taskLogOutputFields = []string{"stdout", "stderr", "response", "responseBody", "error", "errorMessage"}
+
+type taskLogsResponse struct {
+	Logs string `json:"logs"`
+}
+
+// GetTaskLogs collects the logs of given task from its output and,
+// for CI tasks, from the task logs API. Logs are capped to
+// MaxTaskLogBytes. Logs collected so far are returned on error.
+func (csClient *Client) GetTaskLogs(ctx context.Context, execution PipelineExecution, stageName string, taskName string) (string, error) {
+	taskExec := execution.Stages[stageName].Tasks[taskName]
+
+	var logs strings.Builder
+	for _, field := range taskLogOutputFields {
+		value, ok := taskExec.Output[field]
+		if !ok || value == nil {
+			continue
+		}
+		fmt.Fprintf(&logs, "--- %s ---\n%s\n", field, logValue(value))
+	}
+
+	if strings.EqualFold(taskExec.Type, ciTaskType) {
+		ciLogs, err := csClient.getTaskLogs(ctx, execution.ID, stageName, taskName)
+		if err != nil {
+			return truncateLogs(logs.String()), err
+		}
+		if ciLogs != "" {
+			fmt.Fprintf(&logs, "--- logs ---\n%s\n", ciLogs)
+		}
+	}
+	return truncateLogs(logs.String()), nil
+}
+
+// getTaskLogs fetches the task logs from the task logs API.
+// Tasks without logs are not treated as errors.
+func (csClient *Client) getTaskLogs(ctx context.Context, executionID string, stageName string, taskName string) (string, error) {
+	// Fire the request
+	getTaskLogsURL := csClient.APIBaseURL + fmt.Sprintf(taskLogsURL,
+		url.PathEscape(executionID), url.PathEscape(stageName), url.PathEscape(taskName))
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, getTaskLogsURL, headers)
+	})
+	if err == nil && response.Code == http.StatusNotFound {
+		return "", nil
+	}
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return "", fmt.Errorf("Error while getting task logs: %w", err)
+	}
+
+	// Logs are either wrapped in JSON or plain text
+	var logsResponse taskLogsResponse
+	if json.Unmarshal([]byte(response.ResponseString), &logsResponse) == nil {
+		return logsResponse.Logs, nil
+	}
+	return response.ResponseString, nil
+}
+
+// logValue returns strings as they are and everything else as JSON
+func logValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	valueJSONBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(valueJSONBytes)
+}
+
+// truncateLogs keeps the last MaxTaskLogBytes of the logs
+func truncateLogs(logs string) string {
+	if len(logs) <= MaxTaskLogBytes {
+		return logs
+	}
+	return fmt.Sprintf("... %d bytes truncated ...\n", len(logs)-MaxTaskLogBytes) + logs[len(logs)-MaxTaskLogBytes:]
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+)
+
+// newTaskExecution creates execution with a single failed task
+func newTaskExecution(taskType string, output map[string]interface{}) PipelineExecution {
+	return PipelineExecution{
+		ID:         "exec-1",
+		StageOrder: []string{"Build"},
+		Stages: map[string]PipelineStageExecution{
+			"Build": {
+				TaskOrder: []string{"Unit tests"},
+				Tasks: map[string]PipelineTaskExecution{
+					"Unit tests": {Status: StatusFailed, Type: taskType, Output: output},
+				},
+			},
+		},
+	}
+}
+
+func TestGetTaskLogs(t *testing.T) {
+	tests := []struct {
+		name       string
+		taskType   string
+		output     map[string]interface{}
+		statusCode int
+		body       string
+		want       string
+		wantErr    bool
+		wantFetch  bool
+	}{
+		{
+			name:     "SSH output",
+			taskType: "SSH",
+			output:   map[string]interface{}{"stdout": "building", "stderr": "failed", "exitCode": 1},
+			want:     "--- stdout ---\nbuilding\n--- stderr ---\nfailed\n",
+		},
+		{
+			name:     "REST response",
+			taskType: "REST",
+			output:   map[string]interface{}{"responseBody": map[string]interface{}{"error": "bad request"}},
+			want:     "--- responseBody ---\n{\n  \"error\": \"bad request\"\n}\n",
+		},
+		{
+			name:       "CI logs as JSON",
+			taskType:   "CI",
+			statusCode: http.StatusOK,
+			body:       `{"logs":"go test ./...\nFAIL"}`,
+			want:       "--- logs ---\ngo test ./...\nFAIL\n",
+			wantFetch:  true,
+		},
+		{
+			name:       "CI logs as plain text",
+			taskType:   "ci",
+			output:     map[string]interface{}{"error": "exit status 1"},
+			statusCode: http.StatusOK,
+			body:       "go test ./...\nFAIL",
+			want:       "--- error ---\nexit status 1\n--- logs ---\ngo test ./...\nFAIL\n",
+			wantFetch:  true,
+		},
+		{
+			name:       "CI task without logs",
+			taskType:   "CI",
+			statusCode: http.StatusNotFound,
+			want:       "",
+			wantFetch:  true,
+		},
+		{
+			name:       "CI logs failed",
+			taskType:   "CI",
+			output:     map[string]interface{}{"error": "exit status 1"},
+			statusCode: http.StatusInternalServerError,
+			want:       "--- error ---\nexit status 1\n",
+			wantErr:    true,
+			wantFetch:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+				return test.statusCode, test.body
+			}}
+			csClient := newFakeClient(t, transport)
+
+			logs, err := csClient.GetTaskLogs(context.Background(), newTaskExecution(test.taskType, test.output), "Build", "Unit tests")
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetTaskLogs() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if logs != test.want {
+				t.Errorf("GetTaskLogs() = %q, want %q", logs, test.want)
+			}
+
+			requests := transport.Requests()
+			if !test.wantFetch {
+				if len(requests) != 0 {
+					t.Errorf("GetTaskLogs() sent %d requests, want none", len(requests))
+				}
+				return
+			}
+			wantPath := "/codestream/api/executions/exec-1/stages/Build/tasks/Unit tests/logs"
+			if len(requests) != 1 || requests[0].Path != wantPath {
+				t.Errorf("GetTaskLogs() requests = %+v, want GET %s", requests, wantPath)
+			}
+		})
+	}
+}
+
+func TestGetTaskLogsTruncated(t *testing.T) {
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		return http.StatusOK, strings.Repeat("a", MaxTaskLogBytes) + "FAIL"
+	}}
+	csClient := newFakeClient(t, transport)
+
+	logs, err := csClient.GetTaskLogs(context.Background(), newTaskExecution("CI", nil), "Build", "Unit tests")
+	if err != nil {
+		t.Fatalf("GetTaskLogs() error = %v", err)
+	}
+	if !strings.HasPrefix(logs, "... ") || !strings.HasSuffix(logs, "FAIL\n") {
+		t.Errorf("GetTaskLogs() = %q..., want the end of the logs kept", logs[:40])
+	}
+	if len(logs) > MaxTaskLogBytes+64 {
+		t.Errorf("GetTaskLogs() returned %d bytes, want about %d", len(logs), MaxTaskLogBytes)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
+const (
+	pipelineURL = "/codestream/api/pipelines/%s"
+	// PipelineStateEnabled is the state of an enabled pipeline
+	PipelineStateEnabled  = "ENABLED"
+	pipelineStateDisabled = "DISABLED"
+)
+
+// Pipeline holds pipeline definition
+type Pipeline struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Project string `json:"project"`
+	Enabled bool   `json:"enabled"`
+	State   string `json:"state"`
+	// Input holds the declared inputs with their default values
+	Input     map[string]interface{}   `json:"input"`
+	InputMeta map[string]PipelineInput `json:"_inputMeta"`
+}
+
+// PipelineInput holds the declared input details
+type PipelineInput struct {
+	Description string `json:"description"`
+	Mandatory   bool   `json:"mandatory"`
+}
+
+// IsDisabled tells whether the pipeline cannot be executed
+func (pipeline Pipeline) IsDisabled() bool {
+	return !pipeline.Enabled || strings.EqualFold(pipeline.State, pipelineStateDisabled)
+}
+
+// GetPipeline fetches pipeline definition for given pipelineID
+func (csClient *Client) GetPipeline(ctx context.Context, pipelineID string) (Pipeline, error) {
+	// Fire the request
+	getPipelineURL := fmt.Sprintf(csClient.APIBaseURL+pipelineURL, pipelineID)
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, getPipelineURL, headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return Pipeline{}, fmt.Errorf("Error while getting pipeline: %w", err)
+	}
+
+	// Parse the pipeline
+	var pipeline Pipeline
+	err = json.Unmarshal([]byte(response.ResponseString), &pipeline)
+	if err != nil {
+		return Pipeline{}, fmt.Errorf("Error while unmarshalling the pipeline response : %s. %v", response.Message, err)
+	}
+	return pipeline, nil
+}
+
+// UpdatePipelineState sets the enabled flag and state of the pipeline.
+// Code Stream updates a pipeline by PUT of its full definition, so the
+// definition is fetched and sent back with only these fields changed.
+// Read-only fields, prefixed with _, are dropped. Empty state is left
+// as it is.
+func (csClient *Client) UpdatePipelineState(ctx context.Context, pipelineID string, enabled bool, state string) error {
+	// Fetch the full definition
+	pipelineDefURL := fmt.Sprintf(csClient.APIBaseURL+pipelineURL, pipelineID)
+	response, err := csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.GetHeaders(ctx, pipelineDefURL, headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return fmt.Errorf("Error while getting pipeline: %w", err)
+	}
+	var definition map[string]interface{}
+	err = json.Unmarshal([]byte(response.ResponseString), &definition)
+	if err != nil {
+		return fmt.Errorf("Error while unmarshalling the pipeline response : %s. %v", response.Message, err)
+	}
+
+	// Marshal the updated definition to JSON
+	for key := range definition {
+		if strings.HasPrefix(key, "_") {
+			delete(definition, key)
+		}
+	}
+	definition["enabled"] = enabled
+	if state != "" {
+		definition["state"] = state
+	}
+	requestBodyJSONBytes, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+
+	// Fire the request
+	response, err = csClient.fireWithAuth(ctx, func(headers map[string]string) (httpUtils.Response, error) {
+		return csClient.HTTPClient.PutHeaders(ctx, pipelineDefURL, string(requestBodyJSONBytes), headers)
+	})
+	if err := httpUtils.CheckResponse(response, err, http.StatusOK); err != nil {
+		return fmt.Errorf("Error while updating pipeline: %w", err)
+	}
+	return nil
+}
+
+// UnknownInputs returns the sorted input keys which
+// are not declared by the pipeline, e.g. due to a typo
+func UnknownInputs(pipeline Pipeline, inputs map[string]interface{}) []string {
+	var unknown []string
+	for _, key := range SortedKeys(inputs) {
+		if _, declared := pipeline.Input[key]; !declared {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown
+}
+
+// MissingInputs returns the sorted mandatory inputs of the
+// pipeline which have neither a value nor a default value
+func MissingInputs(pipeline Pipeline, inputs map[string]interface{}) []string {
+	var missing []string
+	for _, key := range SortedKeys(pipeline.Input) {
+		if !pipeline.InputMeta[key].Mandatory {
+			continue
+		}
+		value, provided := inputs[key]
+		if !provided {
+			value = pipeline.Input[key]
+		}
+		if isEmptyInput(value) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// ValidateInputs checks the inputs against the pipeline definition.
+// Missing mandatory inputs are always reported, while unknown
+// inputs are reported only if strict, and returned otherwise.
+func ValidateInputs(pipeline Pipeline, inputs map[string]interface{}, strict bool) ([]string, error) {
+	var problems []string
+	if missing := MissingInputs(pipeline, inputs); len(missing) > 0 {
+		problems = append(problems, "mandatory inputs without value: "+strings.Join(missing, ", "))
+	}
+	unknown := UnknownInputs(pipeline, inputs)
+	if strict && len(unknown) > 0 {
+		problems = append(problems, "inputs not declared by the pipeline: "+strings.Join(unknown, ", "))
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Inputs are not valid for pipeline %s: %s", pipeline.Name, strings.Join(problems, "; "))
+	}
+	if strict {
+		return nil, nil
+	}
+	return unknown, nil
+}
+
+// CoerceInputs converts the inputs to the types of the declared
+// inputs, which are implied by their default values. Inputs declared
+// as booleans, numbers, lists or objects accept matching values or
+// strings parsing to them. Inputs declared as strings, which most
+// Code Stream inputs are, accept strings, booleans and numbers, the
+// latter being stringified. Inputs which are not declared or have
+// null default value are passed as they are. All mismatches are
+// reported together.
+func CoerceInputs(pipeline Pipeline, inputs map[string]interface{}) (map[string]interface{}, error) {
+	coerced := make(map[string]interface{}, len(inputs))
+	var mismatches []string
+	for _, key := range SortedKeys(inputs) {
+		value := inputs[key]
+		defaultValue, declared := pipeline.Input[key]
+		if !declared {
+			coerced[key] = value
+			continue
+		}
+
+		coercedValue, err := coerceInput(defaultValue, value)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("input '%s' %s", key, err.Error()))
+			continue
+		}
+		coerced[key] = coercedValue
+	}
+
+	if len(mismatches) > 0 {
+		return nil, errors.New("Inputs do not match the pipeline inputs: " + strings.Join(mismatches, "; "))
+	}
+	return coerced, nil
+}
+
+// coerceInput converts the value to the type of the default value,
+// unless the default value is null
+func coerceInput(defaultValue interface{}, value interface{}) (interface{}, error) {
+	switch defaultValue.(type) {
+	case bool:
+		switch typedValue := value.(type) {
+		case bool:
+			return typedValue, nil
+		case string:
+			if parsed, err := strconv.ParseBool(typedValue); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, fmt.Errorf("expects a boolean, got %s", describeValue(value))
+	case float64:
+		switch typedValue := value.(type) {
+		case float64:
+			return typedValue, nil
+		case string:
+			if parsed, err := strconv.ParseFloat(typedValue, 64); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, fmt.Errorf("expects a number, got %s", describeValue(value))
+	case []interface{}, map[string]interface{}:
+		if str, ok := value.(string); ok {
+			var parsed interface{}
+			if json.Unmarshal([]byte(str), &parsed) != nil {
+				return nil, fmt.Errorf("expects %s, got %s", describeKind(defaultValue), describeValue(value))
+			}
+			value = parsed
+		}
+		if describeKind(value) != describeKind(defaultValue) {
+			return nil, fmt.Errorf("expects %s, got %s", describeKind(defaultValue), describeValue(value))
+		}
+		return value, nil
+	case string:
+		switch typedValue := value.(type) {
+		case nil, string:
+			return typedValue, nil
+		case bool:
+			return strconv.FormatBool(typedValue), nil
+		case float64:
+			return strconv.FormatFloat(typedValue, 'f', -1, 64), nil
+		}
+		return nil, fmt.Errorf("expects a string, got %s", describeValue(value))
+	default:
+		return value, nil
+	}
+}
+
+func isEmptyInput(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(typedValue) == ""
+	default:
+		return false
+	}
+}
+
+func describeKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func describeValue(value interface{}) string {
+	valueJSONBytes, err := json.Marshal(value)
+	if err != nil {
+		return describeKind(value)
+	}
+	return fmt.Sprintf("%s %s", describeKind(value), valueJSONBytes)
+}
+
+// SortedKeys returns the keys of the values in ascending order
+func SortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
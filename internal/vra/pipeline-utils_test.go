@@ -0,0 +1,225 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+)
+
+func TestUpdatePipelineState(t *testing.T) {
+	definition := `{"id":"p1","name":"deploy","enabled":false,"state":"DISABLED",` +
+		`"stages":{"build":{"tasks":{}}},"_link":"/codestream/api/pipelines/p1"}`
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		return http.StatusOK, definition
+	}}
+	csClient := newFakeClient(t, transport)
+
+	err := csClient.UpdatePipelineState(context.Background(), "p1", true, PipelineStateEnabled)
+	if err != nil {
+		t.Fatalf("UpdatePipelineState() error = %v", err)
+	}
+
+	requests := transport.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	get, put := requests[0], requests[1]
+	if get.Method != http.MethodGet || get.Path != "/codestream/api/pipelines/p1" {
+		t.Errorf("first request = %s %s, want GET of the pipeline", get.Method, get.Path)
+	}
+	if put.Method != http.MethodPut || put.Path != "/codestream/api/pipelines/p1" {
+		t.Errorf("second request = %s %s, want PUT of the pipeline", put.Method, put.Path)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(put.Body), &body); err != nil {
+		t.Fatalf("PUT body is not JSON: %v", err)
+	}
+	if body["enabled"] != true || body["state"] != PipelineStateEnabled {
+		t.Errorf("PUT body enabled = %v, state = %v, want true, ENABLED", body["enabled"], body["state"])
+	}
+	if _, ok := body["stages"]; !ok {
+		t.Errorf("PUT body dropped the stages of the definition: %s", put.Body)
+	}
+	if _, ok := body["_link"]; ok {
+		t.Errorf("PUT body kept the read-only _link field: %s", put.Body)
+	}
+}
+
+func TestUpdatePipelineStateKeepsEmptyState(t *testing.T) {
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		return http.StatusOK, `{"id":"p1","enabled":true,"state":"RELEASED"}`
+	}}
+	csClient := newFakeClient(t, transport)
+
+	if err := csClient.UpdatePipelineState(context.Background(), "p1", false, ""); err != nil {
+		t.Fatalf("UpdatePipelineState() error = %v", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(transport.Requests()[1].Body), &body); err != nil {
+		t.Fatalf("PUT body is not JSON: %v", err)
+	}
+	if body["enabled"] != false || body["state"] != "RELEASED" {
+		t.Errorf("PUT body enabled = %v, state = %v, want false, RELEASED", body["enabled"], body["state"])
+	}
+}
+
+func TestUpdatePipelineStateError(t *testing.T) {
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		if request.Method == http.MethodPut {
+			return http.StatusBadRequest, `{"message":"invalid pipeline"}`
+		}
+		return http.StatusOK, `{"id":"p1"}`
+	}}
+	csClient := newFakeClient(t, transport)
+
+	err := csClient.UpdatePipelineState(context.Background(), "p1", true, PipelineStateEnabled)
+	var apiErr *APIError
+	if err == nil {
+		t.Fatal("UpdatePipelineState() error = nil, want API error")
+	}
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("UpdatePipelineState() error = %v, want 400 API error", err)
+	}
+}
+
+func TestCoerceInputs(t *testing.T) {
+	pipeline := Pipeline{Input: map[string]interface{}{
+		"name":   "",
+		"debug":  false,
+		"count":  float64(1),
+		"tags":   []interface{}{},
+		"config": map[string]interface{}{},
+		"extra":  nil,
+	}}
+	tests := []struct {
+		name    string
+		inputs  map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"string kept", map[string]interface{}{"name": "app"}, map[string]interface{}{"name": "app"}, false},
+		{"boolean stringified for string input", map[string]interface{}{"name": true}, map[string]interface{}{"name": "true"}, false},
+		{"number stringified for string input", map[string]interface{}{"name": float64(3)}, map[string]interface{}{"name": "3"}, false},
+		{"decimal stringified for string input", map[string]interface{}{"name": 2.5}, map[string]interface{}{"name": "2.5"}, false},
+		{"list mismatch for string input", map[string]interface{}{"name": []interface{}{"a", true}}, nil, true},
+		{"object mismatch for string input", map[string]interface{}{"name": map[string]interface{}{"a": "b"}}, nil, true},
+		{"null kept for string input", map[string]interface{}{"name": nil}, map[string]interface{}{"name": nil}, false},
+		{"boolean", map[string]interface{}{"debug": true}, map[string]interface{}{"debug": true}, false},
+		{"boolean from string", map[string]interface{}{"debug": "true"}, map[string]interface{}{"debug": true}, false},
+		{"boolean mismatch", map[string]interface{}{"debug": "yes please"}, nil, true},
+		{"number", map[string]interface{}{"count": float64(3)}, map[string]interface{}{"count": float64(3)}, false},
+		{"number from string", map[string]interface{}{"count": "2.5"}, map[string]interface{}{"count": 2.5}, false},
+		{"number mismatch", map[string]interface{}{"count": true}, nil, true},
+		{"list", map[string]interface{}{"tags": []interface{}{"a"}}, map[string]interface{}{"tags": []interface{}{"a"}}, false},
+		{"list from JSON", map[string]interface{}{"tags": `["a","b"]`}, map[string]interface{}{"tags": []interface{}{"a", "b"}}, false},
+		{"list mismatch", map[string]interface{}{"tags": map[string]interface{}{}}, nil, true},
+		{"object from JSON", map[string]interface{}{"config": `{"a":1}`},
+			map[string]interface{}{"config": map[string]interface{}{"a": float64(1)}}, false},
+		{"object invalid JSON", map[string]interface{}{"config": `{a`}, nil, true},
+		{"null default kept", map[string]interface{}{"extra": float64(1)}, map[string]interface{}{"extra": float64(1)}, false},
+		{"undeclared kept", map[string]interface{}{"other": true}, map[string]interface{}{"other": true}, false},
+		{"all mismatches", map[string]interface{}{"debug": 1.0, "count": "many"}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CoerceInputs(pipeline, test.inputs)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("CoerceInputs() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("CoerceInputs() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCoerceInputsReportsAllMismatches(t *testing.T) {
+	pipeline := Pipeline{Input: map[string]interface{}{"debug": false, "count": float64(1)}}
+	_, err := CoerceInputs(pipeline, map[string]interface{}{"debug": 1.0, "count": "many"})
+	if err == nil {
+		t.Fatal("CoerceInputs() error = nil, want mismatches")
+	}
+	for _, key := range []string{"'debug'", "'count'"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("CoerceInputs() error = %q, want it to mention %s", err, key)
+		}
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	pipeline := Pipeline{
+		Name: "deploy",
+		Input: map[string]interface{}{
+			"env":     "",
+			"version": "",
+			"region":  "us-east-1",
+			"debug":   false,
+		},
+		InputMeta: map[string]PipelineInput{
+			"env":     {Mandatory: true},
+			"version": {Mandatory: true},
+			"region":  {Mandatory: true},
+		},
+	}
+	tests := []struct {
+		name        string
+		inputs      map[string]interface{}
+		strict      bool
+		wantUnknown []string
+		wantErr     bool
+	}{
+		{"all mandatory provided", map[string]interface{}{"env": "prod", "version": "1.0"}, true, nil, false},
+		{"default satisfies mandatory", map[string]interface{}{"env": "prod", "version": 2.0}, true, nil, false},
+		{"mandatory missing", map[string]interface{}{"env": "prod"}, true, nil, true},
+		{"mandatory blank", map[string]interface{}{"env": " ", "version": "1.0"}, true, nil, true},
+		{"mandatory null", map[string]interface{}{"env": nil, "version": "1.0"}, true, nil, true},
+		{"mandatory default cleared", map[string]interface{}{"env": "prod", "version": "1.0", "region": ""}, true, nil, true},
+		{"unknown strict", map[string]interface{}{"env": "prod", "version": "1.0", "verison": "1.1"}, true, nil, true},
+		{"unknown not strict", map[string]interface{}{"env": "prod", "version": "1.0", "verison": "1.1", "a": 1}, false,
+			[]string{"a", "verison"}, false},
+		{"mandatory missing not strict", map[string]interface{}{"version": "1.0"}, false, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unknown, err := ValidateInputs(pipeline, test.inputs, test.strict)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ValidateInputs() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(unknown, test.wantUnknown) {
+				t.Errorf("ValidateInputs() unknown = %v, want %v", unknown, test.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestPipelineIsDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline Pipeline
+		want     bool
+	}{
+		{"enabled", Pipeline{Enabled: true, State: "ENABLED"}, false},
+		{"released", Pipeline{Enabled: true, State: "RELEASED"}, false},
+		{"enabled without state", Pipeline{Enabled: true}, false},
+		{"disabled", Pipeline{Enabled: false, State: "DISABLED"}, true},
+		{"not enabled", Pipeline{Enabled: false}, true},
+		{"disabled state", Pipeline{Enabled: true, State: "disabled"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.pipeline.IsDisabled(); got != test.want {
+				t.Errorf("IsDisabled() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is the initial interval between polls
+	DefaultPollInterval = time.Second * 5
+	// DefaultMaxPollInterval is the maximum interval between polls
+	DefaultMaxPollInterval = time.Second * 30
+	// DefaultPollMultiplier grows the interval after every poll
+	DefaultPollMultiplier = 1.5
+)
+
+// PollConfig holds the intervals to poll executions with
+type PollConfig struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+}
+
+// WaitForExecution polls the execution with exponential backoff and jitter
+// until it reaches a terminal status or the context is done. onPoll, when
+// provided, is called with every polled execution. Rate limited polls are
+// retried after the interval asked by the server.
+func (csClient *Client) WaitForExecution(ctx context.Context, executionID string, config PollConfig,
+	onPoll func(PipelineExecution)) (PipelineExecution, error) {
+	config = withPollDefaults(config)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	interval := config.InitialInterval
+	timer := time.NewTimer(withJitter(random, interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return PipelineExecution{}, ctx.Err()
+		case <-timer.C:
+		}
+
+		wait := time.Duration(0)
+		pipelineExec, err := csClient.GetPipelineExecution(ctx, executionID)
+		if err != nil {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+				return PipelineExecution{}, err
+			}
+			wait = apiErr.RetryAfter
+		} else {
+			if onPoll != nil {
+				onPoll(pipelineExec)
+			}
+			if pipelineExec.Status.IsTerminal() {
+				return pipelineExec, nil
+			}
+		}
+
+		// Back off, unless server asked to wait even longer
+		interval = time.Duration(float64(interval) * config.Multiplier)
+		if interval > config.MaxInterval {
+			interval = config.MaxInterval
+		}
+		if jittered := withJitter(random, interval); wait < jittered {
+			wait = jittered
+		}
+		timer.Reset(wait)
+	}
+}
+
+// withPollDefaults fills the missing poll config values
+func withPollDefaults(config PollConfig) PollConfig {
+	if config.InitialInterval <= 0 {
+		config.InitialInterval = DefaultPollInterval
+	}
+	if config.MaxInterval <= 0 {
+		config.MaxInterval = DefaultMaxPollInterval
+	}
+	if config.MaxInterval < config.InitialInterval {
+		config.MaxInterval = config.InitialInterval
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = DefaultPollMultiplier
+	}
+	return config
+}
+
+// withJitter randomizes the interval between its half and
+// itself, so that concurrent pollers do not poll in lockstep
+func withJitter(random *rand.Rand, interval time.Duration) time.Duration {
+	half := interval / 2
+	if half <= 0 {
+		return interval
+	}
+	return half + time.Duration(random.Int63n(int64(half)+1))
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+)
+
+func TestWithPollDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PollConfig
+		want   PollConfig
+	}{
+		{"empty", PollConfig{},
+			PollConfig{DefaultPollInterval, DefaultMaxPollInterval, DefaultPollMultiplier}},
+		{"provided", PollConfig{time.Second, time.Minute, 2},
+			PollConfig{time.Second, time.Minute, 2}},
+		{"max below initial", PollConfig{time.Minute, time.Second, 2},
+			PollConfig{time.Minute, time.Minute, 2}},
+		{"max below default initial", PollConfig{0, time.Second, 0},
+			PollConfig{DefaultPollInterval, DefaultPollInterval, DefaultPollMultiplier}},
+		{"shrinking multiplier", PollConfig{time.Second, time.Minute, 0.5},
+			PollConfig{time.Second, time.Minute, DefaultPollMultiplier}},
+		{"constant interval", PollConfig{time.Second, time.Minute, 1},
+			PollConfig{time.Second, time.Minute, 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := withPollDefaults(test.config); got != test.want {
+				t.Errorf("withPollDefaults() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, interval := range []time.Duration{0, 1, 2, time.Millisecond, time.Second, 30 * time.Second} {
+		for i := 0; i < 100; i++ {
+			got := withJitter(random, interval)
+			if got < interval/2 || got > interval {
+				t.Fatalf("withJitter(%v) = %v, want between %v and %v", interval, got, interval/2, interval)
+			}
+		}
+	}
+}
+
+func TestWaitForExecution(t *testing.T) {
+	statuses := []string{"RUNNING", "", "RUNNING", "COMPLETED"}
+	polls := 0
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		status := statuses[polls]
+		polls++
+		if status == "" {
+			return http.StatusTooManyRequests, `{"message":"slow down"}`
+		}
+		return http.StatusOK, `{"id":"e1","status":"` + status + `"}`
+	}}
+	csClient := newFakeClient(t, transport)
+
+	var polled []ExecutionStatus
+	config := PollConfig{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond, Multiplier: 2}
+	execution, err := csClient.WaitForExecution(context.Background(), "e1", config, func(execution PipelineExecution) {
+		polled = append(polled, execution.Status)
+	})
+	if err != nil {
+		t.Fatalf("WaitForExecution() error = %v", err)
+	}
+	if execution.Status != StatusCompleted {
+		t.Errorf("WaitForExecution() status = %s, want COMPLETED", execution.Status)
+	}
+	if len(transport.Requests()) != len(statuses) {
+		t.Errorf("got %d polls, want %d", len(transport.Requests()), len(statuses))
+	}
+	if len(polled) != 3 || polled[2] != StatusCompleted {
+		t.Errorf("onPoll got %v, want the 3 successful polls", polled)
+	}
+}
+
+func TestWaitForExecutionContextDone(t *testing.T) {
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		return http.StatusOK, `{"id":"e1","status":"RUNNING"}`
+	}}
+	csClient := newFakeClient(t, transport)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	config := PollConfig{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+	_, err := csClient.WaitForExecution(ctx, "e1", config, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("WaitForExecution() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
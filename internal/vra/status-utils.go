@@ -0,0 +1,71 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+// ExecutionStatus is the status of a pipeline, stage or task execution
+type ExecutionStatus string
+
+// Code Stream execution statuses
+const (
+	StatusNotStarted        ExecutionStatus = "NOT_STARTED"
+	StatusQueued            ExecutionStatus = "QUEUED"
+	StatusStarted           ExecutionStatus = "STARTED"
+	StatusRunning           ExecutionStatus = "RUNNING"
+	StatusWaiting           ExecutionStatus = "WAITING"
+	StatusPausing           ExecutionStatus = "PAUSING"
+	StatusPaused            ExecutionStatus = "PAUSED"
+	StatusResuming          ExecutionStatus = "RESUMING"
+	StatusCanceling         ExecutionStatus = "CANCELING"
+	StatusRollingBack       ExecutionStatus = "ROLLING_BACK"
+	StatusCompleted         ExecutionStatus = "COMPLETED"
+	StatusSkipped           ExecutionStatus = "SKIPPED"
+	StatusFailedContinue    ExecutionStatus = "FAILED_CONTINUE"
+	StatusFailed            ExecutionStatus = "FAILED"
+	StatusCanceled          ExecutionStatus = "CANCELED"
+	StatusRollbackCompleted ExecutionStatus = "ROLLBACK_COMPLETED"
+	StatusRollbackFailed    ExecutionStatus = "ROLLBACK_FAILED"
+)
+
+// terminal tells whether each known status is terminal
+var terminal = map[ExecutionStatus]bool{
+	StatusNotStarted:        false,
+	StatusQueued:            false,
+	StatusStarted:           false,
+	StatusRunning:           false,
+	StatusWaiting:           false,
+	StatusPausing:           false,
+	StatusPaused:            false,
+	StatusResuming:          false,
+	StatusCanceling:         false,
+	StatusRollingBack:       false,
+	StatusCompleted:         true,
+	StatusSkipped:           true,
+	StatusFailedContinue:    true,
+	StatusFailed:            true,
+	StatusCanceled:          true,
+	StatusRollbackCompleted: true,
+	StatusRollbackFailed:    true,
+}
+
+// IsKnown tells whether the status is one of the known statuses
+func (status ExecutionStatus) IsKnown() bool {
+	_, ok := terminal[status]
+	return ok
+}
+
+// IsTerminal tells whether the execution has finished.
+// Unknown statuses are not terminal.
+func (status ExecutionStatus) IsTerminal() bool {
+	return terminal[status]
+}
+
+// IsFailed tells whether the execution has finished
+// without completing successfully
+func (status ExecutionStatus) IsFailed() bool {
+	switch status {
+	case StatusFailed, StatusCanceled, StatusRollbackCompleted, StatusRollbackFailed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vra
+
+import "testing"
+
+func TestExecutionStatus(t *testing.T) {
+	tests := []struct {
+		status   ExecutionStatus
+		known    bool
+		terminal bool
+		failed   bool
+	}{
+		{StatusNotStarted, true, false, false},
+		{StatusQueued, true, false, false},
+		{StatusStarted, true, false, false},
+		{StatusRunning, true, false, false},
+		{StatusWaiting, true, false, false},
+		{StatusPausing, true, false, false},
+		{StatusPaused, true, false, false},
+		{StatusResuming, true, false, false},
+		{StatusCanceling, true, false, false},
+		{StatusRollingBack, true, false, false},
+		{StatusCompleted, true, true, false},
+		{StatusSkipped, true, true, false},
+		{StatusFailedContinue, true, true, false},
+		{StatusFailed, true, true, true},
+		{StatusCanceled, true, true, true},
+		{StatusRollbackCompleted, true, true, true},
+		{StatusRollbackFailed, true, true, true},
+		{"SOMETHING_NEW", false, false, false},
+		{"", false, false, false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.status), func(t *testing.T) {
+			if got := test.status.IsKnown(); got != test.known {
+				t.Errorf("IsKnown() = %v, want %v", got, test.known)
+			}
+			if got := test.status.IsTerminal(); got != test.terminal {
+				t.Errorf("IsTerminal() = %v, want %v", got, test.terminal)
+			}
+			if got := test.status.IsFailed(); got != test.failed {
+				t.Errorf("IsFailed() = %v, want %v", got, test.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+// Maximum number of pages of executions fetched while
+// looking for the execution of the current version
+const maxCheckPages = 10
+
+func check(ctx context.Context, source VRASource, version VRAVersion) ([]VRAVersion, error) {
+	csClient, err := newCodeStreamClient(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch Pipeline ID
+	pipelineID, err := getPipelineID(ctx, csClient, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch latest executions of the pipeline
+	executions, complete, err := getExecutionsSince(ctx, csClient, pipelineID, version)
+	if err != nil {
+		return nil, fmt.Errorf("Error while getting pipeline executions:%w", err)
+	}
+	if !complete {
+		log.Printf("Current version %s is older than the latest %d executions, hence versions in between may be skipped", version.ExecutionID, maxCheckPages*vra.ExecutionsPageSize)
+	}
+
+	versions := selectVersions(pipelineID, executions, version)
+	log.Printf("Found %d new versions of vRealize Automation pipeline", len(versions))
+	return versions, nil
+}
+
+// selectVersions returns the completed executions as versions, starting
+// from the current version if it is still present. Otherwise, e.g. when
+// the current execution did not complete, completed executions after it
+// are returned. Without current version, only the latest one is returned.
+func selectVersions(pipelineID string, executions []vra.PipelineExecution, version VRAVersion) []VRAVersion {
+	// Only completed executions are considered as versions
+	versions := []VRAVersion{}
+	for _, execution := range executions {
+		if execution.Status == vra.StatusCompleted {
+			versions = append(versions, newVersion(pipelineID, execution.ID, execution.Index))
+		}
+	}
+	if len(versions) == 0 {
+		return versions
+	}
+
+	for i, v := range versions {
+		if v.ExecutionID == version.ExecutionID {
+			return versions[i:]
+		}
+	}
+	currentIndex, err := strconv.Atoi(version.Index)
+	if version.ExecutionID == "" || err != nil {
+		return versions[len(versions)-1:]
+	}
+	for i, v := range versions {
+		if index, _ := strconv.Atoi(v.Index); index > currentIndex {
+			return versions[i:]
+		}
+	}
+	return []VRAVersion{}
+}
+
+// getExecutionsSince pages back through the executions of the pipeline,
+// from the latest one, until the execution of the current version is
+// passed or maxCheckPages are fetched. Executions are ordered by their
+// index in ascending order. complete tells whether all the executions
+// since the current version are fetched.
+func getExecutionsSince(ctx context.Context, csClient *vra.Client, pipelineID string, version VRAVersion) (executions []vra.PipelineExecution, complete bool, err error) {
+	currentIndex, err := strconv.Atoi(version.Index)
+	if err != nil {
+		// Without current version, latest executions are enough
+		currentIndex = math.MaxInt32
+	}
+
+	seen := make(map[string]bool)
+	for page := 0; page < maxCheckPages && !complete; page++ {
+		pageExecutions, err := csClient.GetPipelineExecutionsPage(ctx, pipelineID, page*vra.ExecutionsPageSize)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Executions created while paging shift the pages
+		for _, execution := range pageExecutions {
+			if !seen[execution.ID] {
+				seen[execution.ID] = true
+				executions = append(executions, execution)
+			}
+		}
+		complete = len(pageExecutions) < vra.ExecutionsPageSize || pageExecutions[0].Index <= currentIndex
+	}
+
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].Index < executions[j].Index
+	})
+	return executions, complete, nil
+}
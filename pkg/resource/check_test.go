@@ -0,0 +1,134 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+func TestSelectVersions(t *testing.T) {
+	executions := []vra.PipelineExecution{
+		{ID: "e1", Index: 1, Status: vra.StatusCompleted},
+		{ID: "e2", Index: 2, Status: vra.StatusFailed},
+		{ID: "e3", Index: 3, Status: vra.StatusCompleted},
+		{ID: "e4", Index: 4, Status: vra.StatusRunning},
+		{ID: "e5", Index: 5, Status: vra.StatusCompleted},
+	}
+	tests := []struct {
+		name     string
+		version  VRAVersion
+		expected []string
+	}{
+		{"no current version", VRAVersion{}, []string{"e5"}},
+		{"current version present", VRAVersion{ExecutionID: "e3", Index: "3"}, []string{"e3", "e5"}},
+		{"current version is latest", VRAVersion{ExecutionID: "e5", Index: "5"}, []string{"e5"}},
+		{"current execution failed", VRAVersion{ExecutionID: "e2", Index: "2"}, []string{"e3", "e5"}},
+		{"current execution not waited for", VRAVersion{ExecutionID: "e4", Index: "4"}, []string{"e5"}},
+		{"current execution dropped out", VRAVersion{ExecutionID: "e0", Index: "0"}, []string{"e1", "e3", "e5"}},
+		{"nothing newer", VRAVersion{ExecutionID: "e6", Index: "6"}, []string{}},
+		{"current version without index", VRAVersion{ExecutionID: "e9"}, []string{"e5"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			versions := selectVersions("p1", executions, test.version)
+			got := []string{}
+			for _, version := range versions {
+				got = append(got, version.ExecutionID)
+				if version.PipelineID != "p1" || version.Index != version.ExecutionID[1:] {
+					t.Errorf("selectVersions() version = %+v, want pipeline p1 and index of %s", version, version.ExecutionID)
+				}
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("selectVersions() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestSelectVersionsWithoutCompleted(t *testing.T) {
+	executions := []vra.PipelineExecution{{ID: "e1", Index: 1, Status: vra.StatusFailed}}
+	if versions := selectVersions("p1", executions, VRAVersion{}); len(versions) != 0 {
+		t.Errorf("selectVersions() = %v, want none", versions)
+	}
+}
+
+// executionPages answers the list executions API with total
+// executions, latest first, paged with $top and $skip
+func executionPages(total int) func(request fake.Request) (int, string) {
+	return func(request fake.Request) (int, string) {
+		top, _ := strconv.Atoi(request.Query.Get("$top"))
+		skip, _ := strconv.Atoi(request.Query.Get("$skip"))
+		documents := map[string]vra.PipelineExecution{}
+		for index := total - skip; index > total-skip-top && index > 0; index-- {
+			id := fmt.Sprintf("e%d", index)
+			documents[id] = vra.PipelineExecution{ID: id, Index: index, Status: vra.StatusCompleted}
+		}
+		body, _ := json.Marshal(vra.PipelineExecutions{Count: len(documents), TotalCount: total, Documents: documents})
+		return http.StatusOK, string(body)
+	}
+}
+
+func TestGetExecutionsSince(t *testing.T) {
+	pageSize := vra.ExecutionsPageSize
+	tests := []struct {
+		name         string
+		total        int
+		version      VRAVersion
+		wantPages    int
+		wantFirst    int
+		wantComplete bool
+	}{
+		{"no current version", 3 * pageSize, VRAVersion{}, 1, 2*pageSize + 1, true},
+		{"fewer than a page", pageSize / 2, VRAVersion{ExecutionID: "e1", Index: "1"}, 1, 1, true},
+		{"current version in first page", 3 * pageSize, VRAVersion{ExecutionID: "e250", Index: "250"}, 1, 2*pageSize + 1, true},
+		{"current version in third page", 3 * pageSize, VRAVersion{ExecutionID: "e50", Index: "50"}, 3, 1, true},
+		{"current version older than the limit", (maxCheckPages + 2) * pageSize, VRAVersion{ExecutionID: "e1", Index: "1"},
+			maxCheckPages, 2*pageSize + 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &fake.Transport{Handle: executionPages(test.total)}
+			csClient := newFakeClient(t, transport)
+
+			executions, complete, err := getExecutionsSince(context.Background(), csClient, "p1", test.version)
+			if err != nil {
+				t.Fatalf("getExecutionsSince() error = %v", err)
+			}
+			if pages := len(transport.Requests()); pages != test.wantPages {
+				t.Errorf("got %d pages, want %d", pages, test.wantPages)
+			}
+			if complete != test.wantComplete {
+				t.Errorf("getExecutionsSince() complete = %v, want %v", complete, test.wantComplete)
+			}
+			if len(executions) == 0 || executions[0].Index != test.wantFirst || executions[len(executions)-1].Index != test.total {
+				t.Fatalf("getExecutionsSince() got %d executions, want %d to %d", len(executions), test.wantFirst, test.total)
+			}
+			for i := 1; i < len(executions); i++ {
+				if executions[i].Index != executions[i-1].Index+1 {
+					t.Fatalf("getExecutionsSince() executions are not ordered without gaps at %d", executions[i].Index)
+				}
+			}
+		})
+	}
+}
+
+func TestGetExecutionsSinceError(t *testing.T) {
+	transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+		return http.StatusServiceUnavailable, `{"message":"unavailable"}`
+	}}
+	csClient := newFakeClient(t, transport)
+
+	if _, _, err := getExecutionsSince(context.Background(), csClient, "p1", VRAVersion{}); err == nil {
+		t.Error("getExecutionsSince() error = nil, want error")
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+// newFakeClient creates Code Stream client sending its requests
+// to given transport, without retries
+func newFakeClient(t *testing.T, transport http.RoundTripper) *vra.Client {
+	t.Helper()
+	httpClient := fake.NewHTTPClient(t, transport)
+	return vra.New(httpClient, fake.APIBaseURL, fake.NewCSPClient(httpClient))
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+const (
+	executionFileName = "execution.json"
+	outputDirName     = "output"
+	stagesDirName     = "stages"
+)
+
+// fileNameReplacer percent encodes path separators and %
+var fileNameReplacer = strings.NewReplacer("%", "%25", "/", "%2F", "\\", "%5C")
+
+func in(ctx context.Context, dir string, source VRASource, version VRAVersion) (interface{}, []interface{}, error) {
+	if version.ExecutionID == "" {
+		return nil, nil, errors.New("Version does not have a pipeline execution ID")
+	}
+
+	// Fetch the pipeline execution
+	log.Println("Fetching vRealize Automation pipeline execution: " + version.ExecutionID)
+	csClient, err := newCodeStreamClient(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	pipelineExec, err := csClient.GetPipelineExecution(ctx, version.ExecutionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while getting pipeline execution:%w", err)
+	}
+
+	// Write the execution record and its outputs
+	err = writeExecution(dir, pipelineExec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while writing pipeline execution:%w", err)
+	}
+
+	// Write logs of failed tasks
+	err = writeTaskLogs(dir, collectFailedTaskLogs(ctx, csClient, pipelineExec))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while writing task logs:%w", err)
+	}
+	log.Println("vRealize Automation pipeline execution is fetched successfully")
+
+	return version, processOutput(pipelineExec), nil
+}
+
+// writeExecution writes the execution record as JSON and
+// one file per pipeline output and per stage/task output
+func writeExecution(dir string, execution vra.PipelineExecution) error {
+	executionJSONBytes, err := json.MarshalIndent(execution, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFile(filepath.Join(dir, executionFileName), executionJSONBytes)
+	if err != nil {
+		return err
+	}
+
+	// Pipeline outputs
+	for outputParam, outputParamVal := range execution.Output {
+		err = writeFile(filepath.Join(dir, outputDirName, sanitizeFileName(outputParam)), []byte(outputParamVal))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stage and task outputs
+	for stageName, stageExec := range execution.Stages {
+		for taskName, taskExec := range stageExec.Tasks {
+			taskDir := filepath.Join(dir, stagesDirName, sanitizeFileName(stageName), sanitizeFileName(taskName))
+			for outputParam, outputParamVal := range taskExec.Output {
+				content, err := outputValueBytes(outputParamVal)
+				if err != nil {
+					return err
+				}
+				err = writeFile(filepath.Join(taskDir, sanitizeFileName(outputParam)), content)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// outputValueBytes returns strings as they are and
+// everything else as JSON
+func outputValueBytes(value interface{}) ([]byte, error) {
+	if str, ok := value.(string); ok {
+		return []byte(str), nil
+	}
+	return json.Marshal(value)
+}
+
+func writeFile(path string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// sanitizeFileName makes stage, task and output names safe to be
+// used as a single path element. Path separators and % are percent
+// encoded, so that different names never end up in the same file.
+func sanitizeFileName(name string) string {
+	switch name {
+	case "":
+		return "%00"
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	}
+	return fileNameReplacer.Replace(name)
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import "testing"
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"deploy", "deploy"},
+		{"a b", "a b"},
+		{"a/b", "a%2Fb"},
+		{"a_b", "a_b"},
+		{`a\b`, "a%5Cb"},
+		{"a%2Fb", "a%252Fb"},
+		{"", "%00"},
+		{".", "%2E"},
+		{"..", "%2E%2E"},
+		{"...", "..."},
+	}
+	seen := make(map[string]string)
+	for _, test := range tests {
+		got := sanitizeFileName(test.name)
+		if got != test.want {
+			t.Errorf("sanitizeFileName(%q) = %q, want %q", test.name, got, test.want)
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("sanitizeFileName(%q) collides with %q", test.name, other)
+		}
+		seen[got] = test.name
+	}
+}
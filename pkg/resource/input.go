@@ -0,0 +1,74 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadInputs merges the pipeline inputs from the params. Inputs from
+// inputFile are overridden by input, which are in turn overridden by
+// inputFrom. Paths are relative to the build directory.
+func loadInputs(dir string, params OutParams) (map[string]interface{}, error) {
+	inputs := make(map[string]interface{})
+
+	// Inputs from JSON/YAML file
+	if params.InputFile != "" {
+		fileInputs, err := readInputFile(filepath.Join(dir, params.InputFile))
+		if err != nil {
+			return nil, fmt.Errorf("Error while reading inputFile %s:%w", params.InputFile, err)
+		}
+		for key, value := range fileInputs {
+			inputs[key] = value
+		}
+	}
+
+	// Literal inputs, which can reference build metadata
+	for key, value := range params.Input {
+		inputs[key] = interpolateValue(value)
+	}
+
+	// Inputs from files produced by earlier steps, e.g. git SHA
+	for key, path := range params.InputFrom {
+		content, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return nil, fmt.Errorf("Error while reading inputFrom %s for input '%s':%w", path, key, err)
+		}
+		inputs[key] = strings.TrimSpace(string(content))
+	}
+	return inputs, nil
+}
+
+// readInputFile reads key-value pairs from JSON/YAML file. Values are
+// normalised to their JSON types, as YAML decodes e.g. integers as int.
+func readInputFile(path string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON
+	var yamlInputs map[string]interface{}
+	err = yaml.Unmarshal(content, &yamlInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	inputsJSONBytes, err := json.Marshal(yamlInputs)
+	if err != nil {
+		return nil, err
+	}
+	var inputs map[string]interface{}
+	err = json.Unmarshal(inputsJSONBytes, &inputs)
+	if err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newBuildDir creates a build directory with given files
+func newBuildDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "inputs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadInputs(t *testing.T) {
+	dir := newBuildDir(t, map[string]string{
+		"vars/inputs.yml": "env: staging\nreplicas: 2\nratio: 0.5\ndebug: true\ntags: [a, b]\nconfig:\n  port: 8080\nregion: eu\n",
+		"git/ref":         "  3f2a9c1\n\n",
+		"image/digest":    "sha256:abc\n",
+	})
+	params := OutParams{
+		InputFile: "vars/inputs.yml",
+		Input:     map[string]interface{}{"env": "prod", "image": "overridden"},
+		InputFrom: map[string]string{"region": "git/ref", "image": "image/digest"},
+	}
+
+	inputs, err := loadInputs(dir, params)
+	if err != nil {
+		t.Fatalf("loadInputs() error = %v", err)
+	}
+	want := map[string]interface{}{
+		// input overrides inputFile
+		"env": "prod",
+		// YAML integers are normalised to JSON numbers
+		"replicas": float64(2),
+		"ratio":    0.5,
+		"debug":    true,
+		"tags":     []interface{}{"a", "b"},
+		"config":   map[string]interface{}{"port": float64(8080)},
+		// inputFrom overrides both, with whitespace trimmed
+		"region": "3f2a9c1",
+		"image":  "sha256:abc",
+	}
+	if !reflect.DeepEqual(inputs, want) {
+		t.Errorf("loadInputs() = %#v, want %#v", inputs, want)
+	}
+}
+
+func TestLoadInputsJSONFile(t *testing.T) {
+	dir := newBuildDir(t, map[string]string{"inputs.json": `{"replicas": 3, "name": "app"}`})
+	inputs, err := loadInputs(dir, OutParams{InputFile: "inputs.json"})
+	if err != nil {
+		t.Fatalf("loadInputs() error = %v", err)
+	}
+	want := map[string]interface{}{"replicas": float64(3), "name": "app"}
+	if !reflect.DeepEqual(inputs, want) {
+		t.Errorf("loadInputs() = %#v, want %#v", inputs, want)
+	}
+}
+
+func TestLoadInputsErrors(t *testing.T) {
+	dir := newBuildDir(t, map[string]string{"invalid.yml": "env: [unclosed\n"})
+	tests := []struct {
+		name    string
+		params  OutParams
+		wantErr string
+	}{
+		{"missing inputFile", OutParams{InputFile: "missing.yml"}, "Error while reading inputFile missing.yml"},
+		{"invalid inputFile", OutParams{InputFile: "invalid.yml"}, "Error while reading inputFile invalid.yml"},
+		{"missing inputFrom", OutParams{InputFrom: map[string]string{"ref": "git/ref"}}, "Error while reading inputFrom git/ref for input 'ref'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loadInputs(dir, test.params)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("loadInputs() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+const (
+	logsDirName      = "logs"
+	logFileExtension = ".log"
+	// Caps the logs printed for all the tasks together,
+	// as every failed task is capped on its own
+	maxPrintedLogBytes = 4 * vra.MaxTaskLogBytes
+)
+
+// taskLogs holds the logs of a task
+type taskLogs struct {
+	stageName string
+	taskName  string
+	logs      string
+}
+
+// collectFailedTaskLogs fetches the logs of every failed task. Logs are
+// best effort, hence failures to fetch them are only reported inline.
+func collectFailedTaskLogs(ctx context.Context, csClient *vra.Client, execution vra.PipelineExecution) []taskLogs {
+	var collected []taskLogs
+	for _, stageName := range execution.StageOrder {
+		stageExec := execution.Stages[stageName]
+		for _, taskName := range stageExec.TaskOrder {
+			if !stageExec.Tasks[taskName].Status.IsFailed() {
+				continue
+			}
+			logs, err := csClient.GetTaskLogs(ctx, execution, stageName, taskName)
+			if err != nil {
+				logs += fmt.Sprintf("--- Error while fetching logs ---\n%s\n", err.Error())
+			}
+			if logs != "" {
+				collected = append(collected, taskLogs{stageName: stageName, taskName: taskName, logs: logs})
+			}
+		}
+	}
+	return collected
+}
+
+// printTaskLogs prints the task logs to stderr, which is shown
+// in the Concourse build log, up to maxPrintedLogBytes in total
+func printTaskLogs(collected []taskLogs) {
+	printedBytes := 0
+	for i, taskLogs := range collected {
+		if printedBytes > 0 && printedBytes+len(taskLogs.logs) > maxPrintedLogBytes {
+			fmt.Fprintf(os.Stderr, "===== Logs of %d more failed tasks are omitted, get the execution to find them under %s =====\n", len(collected)-i, logsDirName)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "===== Logs of Stage %s > Task %s =====\n%s\n", taskLogs.stageName, taskLogs.taskName, taskLogs.logs)
+		printedBytes += len(taskLogs.logs)
+	}
+}
+
+// writeTaskLogs writes one file per task
+// under logs/<stage>/<task>.log
+func writeTaskLogs(dir string, collected []taskLogs) error {
+	for _, taskLogs := range collected {
+		logFile := filepath.Join(dir, logsDirName, sanitizeFileName(taskLogs.stageName), sanitizeFileName(taskLogs.taskName)+logFileExtension)
+		err := writeFile(logFile, []byte(taskLogs.logs))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+const (
+	metadataSeparator = "~"
+	customTaskType    = "CUSTOM"
+)
+
+// taskOutputFields holds the useful output fields
+// of each Code Stream task type, keyed by upper cased
+// task type. Custom integration outputs are user
+// defined, hence all of them are added.
+var taskOutputFields = map[string][]string{
+	"REST":          {"responseCode", "responseHeaders"},
+	"POLL":          {"responseCode", "pollCount"},
+	"SSH":           {"exitCode", "responseFilePath"},
+	"POWERSHELL":    {"exitCode", "responseFilePath"},
+	"K8S":           {"kind", "name", "namespace"},
+	"CI":            {"jobId", "exports"},
+	"JENKINS":       {"jobUrl", "jobId", "jobName"},
+	"VRO":           {"executionId", "executionUrl", "workflowId"},
+	"BLUEPRINT":     {"deploymentId", "deploymentName", "deploymentDetailsLink"},
+	"PIPELINE":      {"executionId", "executionIndex", "executionLink", "status"},
+	"USEROPERATION": {"respondedBy", "responseMessage", "status"},
+	"CONDITION":     {"result"},
+}
+
+func processOutput(execution vra.PipelineExecution) []interface{} {
+	metadataSlice := []interface{}{}
+
+	// Add execution ID and overall pipeline status
+	metadataSlice = append(metadataSlice, MetadataField{Name: "executionId", Value: execution.ID})
+	metadataSlice = append(metadataSlice, MetadataField{Name: "status", Value: string(execution.Status)})
+
+	// Add Output params
+	for _, outputParam := range sortedOutputKeys(execution.Output) {
+		metadataSlice = append(metadataSlice, MetadataField{Name: "output" + metadataSeparator + outputParam, Value: execution.Output[outputParam]})
+	}
+
+	// Add stage and tasks execution details
+	for _, stageName := range execution.StageOrder {
+		stageExec := execution.Stages[stageName]
+		for _, taskName := range stageExec.TaskOrder {
+			taskExec := stageExec.Tasks[taskName]
+			prefix := stageName + metadataSeparator + taskName + metadataSeparator
+
+			// Add task status
+			metadataSlice = append(metadataSlice, MetadataField{Name: prefix + "status", Value: string(taskExec.Status)})
+
+			// Based on task type, add additional data
+			for _, field := range taskMetadataFields(taskExec) {
+				value, ok := formatOutputValue(taskExec.Output[field])
+				if ok {
+					metadataSlice = append(metadataSlice, MetadataField{Name: prefix + field, Value: value})
+				}
+			}
+		}
+	}
+
+	return metadataSlice
+}
+
+// taskMetadataFields returns the output fields to be
+// added as metadata for the type of given task
+func taskMetadataFields(taskExec vra.PipelineTaskExecution) []string {
+	taskType := strings.ToUpper(taskExec.Type)
+	if taskType == customTaskType {
+		return vra.SortedKeys(taskExec.Output)
+	}
+	return taskOutputFields[taskType]
+}
+
+// formatOutputValue converts the task output value to
+// metadata value. Missing values are not added.
+func formatOutputValue(value interface{}) (string, bool) {
+	switch typedValue := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return typedValue, true
+	case bool:
+		return strconv.FormatBool(typedValue), true
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64), true
+	default:
+		valueJSONBytes, err := json.Marshal(typedValue)
+		if err != nil {
+			return "", false
+		}
+		return string(valueJSONBytes), true
+	}
+}
+
+// sortedOutputKeys returns the pipeline output names in ascending order
+func sortedOutputKeys(outputs map[string]string) []string {
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+func TestFormatOutputValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   string
+		wantOK bool
+	}{
+		{"missing", nil, "", false},
+		{"string", "https://jenkins/job/1", "https://jenkins/job/1", true},
+		{"empty string", "", "", true},
+		{"boolean", true, "true", true},
+		{"integer number", float64(200), "200", true},
+		{"decimal number", 1.5, "1.5", true},
+		{"list", []interface{}{"a", float64(1)}, `["a",1]`, true},
+		{"object", map[string]interface{}{"url": "https://jenkins"}, `{"url":"https://jenkins"}`, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := formatOutputValue(test.value)
+			if got != test.want || ok != test.wantOK {
+				t.Errorf("formatOutputValue(%v) = %q, %v, want %q, %v", test.value, got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestProcessOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks map[string]vra.PipelineTaskExecution
+		want  []MetadataField
+	}{
+		{
+			name: "non-string Jenkins jobUrl",
+			tasks: map[string]vra.PipelineTaskExecution{"task": {Status: vra.StatusCompleted, Type: "Jenkins",
+				Output: map[string]interface{}{"jobUrl": map[string]interface{}{"href": "https://jenkins/job/1"}, "jobId": float64(7)}}},
+			want: []MetadataField{
+				{Name: "Stage~task~status", Value: "COMPLETED"},
+				{Name: "Stage~task~jobUrl", Value: `{"href":"https://jenkins/job/1"}`},
+				{Name: "Stage~task~jobId", Value: "7"},
+			},
+		},
+		{
+			name: "missing fields",
+			tasks: map[string]vra.PipelineTaskExecution{"task": {Status: vra.StatusFailed, Type: "REST",
+				Output: map[string]interface{}{"responseHeaders": nil}}},
+			want: []MetadataField{
+				{Name: "Stage~task~status", Value: "FAILED"},
+			},
+		},
+		{
+			name: "custom task adds all outputs",
+			tasks: map[string]vra.PipelineTaskExecution{"task": {Status: vra.StatusCompleted, Type: "Custom",
+				Output: map[string]interface{}{"version": "1.2", "approved": true, "empty": nil}}},
+			want: []MetadataField{
+				{Name: "Stage~task~status", Value: "COMPLETED"},
+				{Name: "Stage~task~approved", Value: "true"},
+				{Name: "Stage~task~version", Value: "1.2"},
+			},
+		},
+		{
+			name: "unknown task type",
+			tasks: map[string]vra.PipelineTaskExecution{"task": {Status: vra.StatusCompleted, Type: "NEW_TYPE",
+				Output: map[string]interface{}{"anything": "value"}}},
+			want: []MetadataField{
+				{Name: "Stage~task~status", Value: "COMPLETED"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			execution := vra.PipelineExecution{ID: "e1", Status: vra.StatusCompleted,
+				Output:     map[string]string{"url": "https://app", "digest": "sha256:1"},
+				StageOrder: []string{"Stage"},
+				Stages: map[string]vra.PipelineStageExecution{
+					"Stage": {TaskOrder: []string{"task"}, Tasks: test.tasks},
+				}}
+			want := []interface{}{
+				MetadataField{Name: "executionId", Value: "e1"},
+				MetadataField{Name: "status", Value: "COMPLETED"},
+				MetadataField{Name: "output~digest", Value: "sha256:1"},
+				MetadataField{Name: "output~url", Value: "https://app"},
+			}
+			for _, field := range test.want {
+				want = append(want, field)
+			}
+
+			got := processOutput(execution)
+			for i, field := range got {
+				if field == nil {
+					t.Fatalf("processOutput() has nil entry at %d", i)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("processOutput() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestProcessOutputWithoutOutputs(t *testing.T) {
+	got := processOutput(vra.PipelineExecution{ID: "e1", Status: vra.StatusFailed})
+	want := []interface{}{
+		MetadataField{Name: "executionId", Value: "e1"},
+		MetadataField{Name: "status", Value: "FAILED"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("processOutput() = %v, want %v", got, want)
+	}
+}
@@ -4,55 +4,118 @@
 package resource
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
-	"github.com/vmware/concourse-vrealize-automation-resource/internal/csp"
 	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
 )
 
 const (
 	defaultWaitTimeoutMinutes = 1440 // 24 hours
-	pollIntervalSeconds       = 30
+	// Time given to cancel the execution, as the build
+	// context is already done when it is canceled
+	cancelExecutionTimeout = time.Second * 30
+	// Time given to disable the pipeline again
+	restorePipelineTimeout = time.Second * 30
 )
 
-func out(source VRASource, params OutParams) (version interface{}, metadata []interface{}, err error) {
+func out(ctx context.Context, dir string, source VRASource, params OutParams) (version interface{}, metadata []interface{}, err error) {
 	// Authenticate
 	log.Println("Authenticating with vRealize Automation...")
-	cspClient := csp.New(source.APIToken)
+	csClient, err := newCodeStreamClient(source)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error while authenticating:%w", err)
 	}
 	log.Println("vRealize Automation authentication is successful")
+	return triggerPipeline(ctx, csClient, dir, source, params)
+}
 
+// triggerPipeline executes the pipeline and, if wait is set, waits
+// for the execution to reach a terminal status
+func triggerPipeline(ctx context.Context, csClient *vra.Client, dir string, source VRASource, params OutParams) (version interface{}, metadata []interface{}, err error) {
 	// Fetch Pipeline ID
-	log.Println("Fetching pipeline ID from name...")
-	csClient := vra.New(cspClient)
-	pipelineID, err := csClient.GetPipelineIDFromName(source.Pipeline)
+	log.Println("Fetching pipeline ID...")
+	pipelineID, err := getPipelineID(ctx, csClient, source)
 	if err != nil {
-		return nil, nil, fmt.Errorf("Error while getting pipeline ID from name:%w", err)
+		return nil, nil, err
 	}
 	log.Println("Pipeline ID is fetched successfully: " + pipelineID)
 
-	// Execute vRealize Automation pipeline
-	log.Println("Triggering vRealize Automation pipeline...")
+	// Fetch pipeline definition
+	pipeline, err := csClient.GetPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while getting pipeline definition:%w", err)
+	}
+
+	// Disabled pipelines cannot be executed. It can be enabled only
+	// if waiting, so that it is disabled again once the execution
+	// has completed.
+	if pipeline.IsDisabled() {
+		if !params.EnableIfDisabled {
+			return nil, nil, fmt.Errorf("vRealize Automation pipeline %s is disabled (state: %s). Enable the pipeline or set enableIfDisabled to true", pipeline.Name, pipeline.State)
+		}
+		if !params.Wait {
+			return nil, nil, fmt.Errorf("vRealize Automation pipeline %s is disabled (state: %s). enableIfDisabled requires wait to be true, so that the pipeline is disabled again once the execution completes", pipeline.Name, pipeline.State)
+		}
+	}
 
 	// Construct pipeline input params
-	exeReq := vra.PipelineExecutionReq{Comments: "Triggered by Concourse CI",
-		Input: params.Input}
-	execResp, err := csClient.ExecutePipeline(pipelineID, exeReq)
+	input, err := loadInputs(dir, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	unknownInputs, err := vra.ValidateInputs(pipeline, input, isStrictInputs(params))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(unknownInputs) > 0 {
+		log.Println("Warning: inputs not declared by the pipeline are passed as strictInputs is set to false: " + strings.Join(unknownInputs, ", "))
+	}
+	input, err = vra.CoerceInputs(pipeline, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Disabled pipeline is enabled only for this execution. Its state
+	// is restored once nothing runs anymore, i.e. when executing fails,
+	// the execution is canceled or reaches a terminal status. Otherwise
+	// it is left enabled so that the execution can go on.
+	executionRunning := false
+	if pipeline.IsDisabled() {
+		log.Println("Enabling vRealize Automation pipeline as it is disabled...")
+		err = csClient.UpdatePipelineState(ctx, pipelineID, true, vra.PipelineStateEnabled)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error while enabling vRealize Automation pipeline:%w", err)
+		}
+		log.Println("vRealize Automation pipeline is enabled successfully")
+		defer func() {
+			if executionRunning {
+				log.Println("vRealize Automation pipeline is left enabled as its execution has not completed. Disable it once it is done")
+				return
+			}
+			restorePipelineState(csClient, pipeline)
+		}()
+	}
+
+	// Execute vRealize Automation pipeline
+	log.Println("Triggering vRealize Automation pipeline...")
+	exeReq := vra.PipelineExecutionReq{Comments: executionComments(params),
+		Input: input}
+	execResp, err := csClient.ExecutePipeline(ctx, pipelineID, exeReq)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error while executing vRealize Automation pipeline:%w", err)
 	}
 	log.Println("vRealize Automation pipeline is triggered successfully")
+	executionRunning = true
+	version = newVersion(pipelineID, execResp.ExecutionID, execResp.ExecutionIndex)
 
 	// Do not wait for the execution to be completed if wait is set to false
 	if !params.Wait {
-		var metadataSlice []interface{} = make([]interface{}, 1)
-		metadataSlice = append(metadataSlice, MetadataField{Name: "executionId", Value: execResp.ExecutionID})
-		return VRAVersion{Value: "TODO"}, metadataSlice, nil
+		return version, []interface{}{MetadataField{Name: "executionId", Value: execResp.ExecutionID}}, nil
 	}
 
 	// Use timeout value from config if provided
@@ -64,78 +127,123 @@ func out(source VRASource, params OutParams) (version interface{}, metadata []in
 	}
 
 	// Wait for the execution to finish with timeout
-	// Channels for polling and timing out
-	pollChannel := time.Tick(time.Second * pollIntervalSeconds)
-	timeoutChannel := time.After(time.Minute * time.Duration(finalWaitTimeout))
-
-	// Buffered channels to receive pipeline execution and errors
-	pipelineExecChannel := make(chan vra.PipelineExecution, 1)
-	errorChannel := make(chan error, 1)
-
-	// Keep getting the latest status of pipeline execution
-	// in a separate Go routine
-	go func() {
-		for {
-			select {
-			case <-timeoutChannel:
-				errorChannel <- errors.New("Timedout while waiting for vRealize Automation pipeline to complete")
-				return
-			case <-pollChannel:
-				pipelineExec, err := csClient.GetPipelineExecution(execResp.ExecutionID)
-				if err != nil {
-					errorChannel <- fmt.Errorf("Error while getting pipeline status::%w", err)
-					return
-				}
-				log.Println("vRealize Automation pipeline's current status: " + pipelineExec.Status)
-				if pipelineExec.Status == "COMPLETED" || pipelineExec.Status == "FAILED" {
-					pipelineExecChannel <- pipelineExec
-					return
-				}
-			}
-		}
-	}()
+	waitCtx, cancelWait := context.WithTimeout(ctx, time.Minute*time.Duration(finalWaitTimeout))
+	defer cancelWait()
 
 	log.Println("Waiting for vRealize Automation pipeline to complete...")
-	select {
-	case pipelineExec := <-pipelineExecChannel:
-		// Executions is either completed or failed
-		log.Println("vRealize Automation pipeline finished execution with status: " + pipelineExec.Status)
-		outputMeta := processOutput(pipelineExec)
-		return VRAVersion{Value: "TODO"}, outputMeta, nil
-	case err := <-errorChannel:
-		return VRAVersion{Value: "TODO"}, nil, err
+	progress := newProgressLogger()
+	pipelineExec, err := csClient.WaitForExecution(waitCtx, execResp.ExecutionID, pollConfig(params), progress.logProgress)
+	switch {
+	case ctx.Err() != nil:
+		executionRunning = !cancelExecution(csClient, params, execResp.ExecutionID, "Concourse build was aborted")
+		return version, nil, errors.New("Aborted while waiting for vRealize Automation pipeline to complete")
+	case waitCtx.Err() != nil:
+		executionRunning = !cancelExecution(csClient, params, execResp.ExecutionID, "waiting for the execution timed out")
+		return version, nil, errors.New("Timedout while waiting for vRealize Automation pipeline to complete")
+	case err != nil:
+		return version, nil, fmt.Errorf("Error while getting pipeline status::%w", err)
+	}
+
+	// Executions has reached a terminal status
+	log.Println("vRealize Automation pipeline finished execution with status: " + string(pipelineExec.Status))
+	executionRunning = false
+	if pipelineExec.Status.IsFailed() {
+		printTaskLogs(collectFailedTaskLogs(ctx, csClient, pipelineExec))
+	}
+	if pipelineExec.Status.IsFailed() && shouldFailOnError(params) {
+		return version, nil, executionError(pipelineExec)
+	}
+	outputMeta := processOutput(pipelineExec)
+	return version, outputMeta, nil
+}
+
+// pollConfig constructs poll config from the params.
+// Missing values fall back to the defaults.
+func pollConfig(params OutParams) vra.PollConfig {
+	return vra.PollConfig{
+		InitialInterval: time.Second * time.Duration(params.PollInterval),
+		MaxInterval:     time.Second * time.Duration(params.MaxPollInterval),
+		Multiplier:      params.PollMultiplier,
 	}
 }
 
-func processOutput(execution vra.PipelineExecution) []interface{} {
-	var metadataSlice []interface{} = make([]interface{}, 1)
+// cancelExecution cancels the remote execution with the given
+// reason if cancelOnAbort is set, and tells whether it got canceled.
+// Failures are only logged so that the original error is reported.
+func cancelExecution(csClient *vra.Client, params OutParams, executionID string, reason string) bool {
+	if !params.CancelOnAbort {
+		log.Println("vRealize Automation pipeline execution is left running as cancelOnAbort is not set")
+		return false
+	}
 
-	// Add execution ID and overall pipeline status
-	metadataSlice = append(metadataSlice, MetadataField{Name: "executionId", Value: execution.ID})
-	metadataSlice = append(metadataSlice, MetadataField{Name: "status", Value: execution.Status})
+	log.Println("Canceling vRealize Automation pipeline execution as " + reason + "...")
+	ctx, cancel := context.WithTimeout(context.Background(), cancelExecutionTimeout)
+	defer cancel()
+	err := csClient.CancelExecution(ctx, executionID, "Canceled by Concourse CI: "+reason)
+	if err != nil {
+		log.Println("Error while canceling vRealize Automation pipeline execution: " + err.Error())
+		return false
+	}
+	log.Println("vRealize Automation pipeline execution is canceled successfully")
+	return true
+}
 
-	// Add Output params
-	for outputParam, outputParamVal := range execution.Output {
-		metadataSlice = append(metadataSlice, MetadataField{Name: "output~" + outputParam, Value: outputParamVal})
+// restorePipelineState restores the enabled flag and state of the
+// pipeline as it was fetched. It uses its own context, as the build
+// context may be done by then. Failures are only logged so that
+// the execution result is reported.
+func restorePipelineState(csClient *vra.Client, pipeline vra.Pipeline) {
+	log.Println("Disabling vRealize Automation pipeline to restore its state...")
+	ctx, cancel := context.WithTimeout(context.Background(), restorePipelineTimeout)
+	defer cancel()
+	err := csClient.UpdatePipelineState(ctx, pipeline.ID, pipeline.Enabled, pipeline.State)
+	if err != nil {
+		log.Println("Error while disabling vRealize Automation pipeline: " + err.Error())
+		return
+	}
+	log.Println("vRealize Automation pipeline is disabled successfully")
+}
+
+// shouldFailOnError defaults to failing the step
+// when failOnError is not provided
+func shouldFailOnError(params OutParams) bool {
+	return params.FailOnError == nil || *params.FailOnError
+}
+
+// isStrictInputs defaults to rejecting unknown
+// inputs when strictInputs is not provided
+func isStrictInputs(params OutParams) bool {
+	return params.StrictInputs == nil || *params.StrictInputs
+}
+
+// executionError constructs error with the failing
+// stage and task of the execution, if any
+func executionError(execution vra.PipelineExecution) error {
+	var reason strings.Builder
+	fmt.Fprintf(&reason, "vRealize Automation pipeline execution %s ended with status %s", execution.ID, execution.Status)
+	if execution.StatusMessage != "" {
+		fmt.Fprintf(&reason, ": %s", execution.StatusMessage)
 	}
 
-	// Add stage and tasks execution details
 	for _, stageName := range execution.StageOrder {
 		stageExec := execution.Stages[stageName]
 		for _, taskName := range stageExec.TaskOrder {
 			taskExec := stageExec.Tasks[taskName]
-
-			// Add task status
-			metadataSlice = append(metadataSlice, MetadataField{Name: stageName + "~" + taskName + "~status", Value: taskExec.Status})
-
-			// Based on task type, add additional data
-			switch taskExec.Type {
-			case "Jenkins":
-				metadataSlice = append(metadataSlice, MetadataField{Name: stageName + "~" + taskName + "~jobUrl", Value: taskExec.Output["jobUrl"].(string)})
-				// TODO: Add more cases for other task types
+			if taskExec.Status.IsFailed() {
+				fmt.Fprintf(&reason, ". Stage %s > Task %s: %s", stageName, taskName, taskExec.Status)
+				if taskExec.StatusMessage != "" {
+					fmt.Fprintf(&reason, ": %s", taskExec.StatusMessage)
+				}
+				return errors.New(reason.String())
+			}
+		}
+		if stageExec.Status.IsFailed() {
+			fmt.Fprintf(&reason, ". Stage %s: %s", stageName, stageExec.Status)
+			if stageExec.StatusMessage != "" {
+				fmt.Fprintf(&reason, ": %s", stageExec.StatusMessage)
 			}
+			return errors.New(reason.String())
 		}
 	}
-
-	return metadataSlice
-}
\ No newline at end of file
+	return errors.New(reason.String())
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/fake"
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+func TestExecutionError(t *testing.T) {
+	tests := []struct {
+		name      string
+		execution vra.PipelineExecution
+		want      string
+	}{
+		{
+			name: "failed task",
+			execution: vra.PipelineExecution{ID: "e1", Status: vra.StatusFailed, StatusMessage: "Deploy failed",
+				StageOrder: []string{"Build", "Deploy"},
+				Stages: map[string]vra.PipelineStageExecution{
+					"Build": {Status: vra.StatusCompleted, TaskOrder: []string{"compile"},
+						Tasks: map[string]vra.PipelineTaskExecution{"compile": {Status: vra.StatusCompleted}}},
+					"Deploy": {Status: vra.StatusFailed, StatusMessage: "stage failed", TaskOrder: []string{"helm", "smoke"},
+						Tasks: map[string]vra.PipelineTaskExecution{
+							"helm":  {Status: vra.StatusFailed, StatusMessage: "exit code 1"},
+							"smoke": {Status: vra.StatusFailed},
+						}},
+				}},
+			want: "vRealize Automation pipeline execution e1 ended with status FAILED: Deploy failed. Stage Deploy > Task helm: FAILED: exit code 1",
+		},
+		{
+			name: "failed task without message",
+			execution: vra.PipelineExecution{ID: "e1", Status: vra.StatusRollbackCompleted,
+				StageOrder: []string{"Deploy"},
+				Stages: map[string]vra.PipelineStageExecution{
+					"Deploy": {Status: vra.StatusFailed, TaskOrder: []string{"helm"},
+						Tasks: map[string]vra.PipelineTaskExecution{"helm": {Status: vra.StatusCanceled}}},
+				}},
+			want: "vRealize Automation pipeline execution e1 ended with status ROLLBACK_COMPLETED. Stage Deploy > Task helm: CANCELED",
+		},
+		{
+			name: "failed stage",
+			execution: vra.PipelineExecution{ID: "e1", Status: vra.StatusFailed,
+				StageOrder: []string{"Deploy"},
+				Stages: map[string]vra.PipelineStageExecution{
+					"Deploy": {Status: vra.StatusFailed, StatusMessage: "precondition not met"},
+				}},
+			want: "vRealize Automation pipeline execution e1 ended with status FAILED. Stage Deploy: FAILED: precondition not met",
+		},
+		{
+			name:      "failed pipeline only",
+			execution: vra.PipelineExecution{ID: "e1", Status: vra.StatusCanceled, StatusMessage: "Canceled by admin"},
+			want:      "vRealize Automation pipeline execution e1 ended with status CANCELED: Canceled by admin",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := executionError(test.execution).Error(); got != test.want {
+				t.Errorf("executionError() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestShouldFailOnError(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name        string
+		failOnError *bool
+		want        bool
+	}{
+		{"not provided", nil, true},
+		{"true", &enabled, true},
+		{"false", &disabled, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := shouldFailOnError(OutParams{FailOnError: test.failOnError}); got != test.want {
+				t.Errorf("shouldFailOnError() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// pipelineStates returns the enabled flag and state of every
+// update of the pipeline definition, in the order they were sent
+func pipelineStates(t *testing.T, transport *fake.Transport) []string {
+	t.Helper()
+	var states []string
+	for _, request := range transport.Requests() {
+		if request.Method != http.MethodPut {
+			continue
+		}
+		var body struct {
+			Enabled bool   `json:"enabled"`
+			State   string `json:"state"`
+		}
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			t.Fatalf("PUT body is not JSON: %v", err)
+		}
+		if body.Enabled {
+			states = append(states, "enabled "+body.State)
+		} else {
+			states = append(states, "disabled "+body.State)
+		}
+	}
+	return states
+}
+
+func TestTriggerPipelineRestoresPipelineState(t *testing.T) {
+	restored := []string{"enabled ENABLED", "disabled DISABLED"}
+	leftEnabled := []string{"enabled ENABLED"}
+
+	tests := []struct {
+		name          string
+		executeStatus int
+		abort         bool
+		cancelOnAbort bool
+		cancelStatus  int
+		wantErr       string
+		want          []string
+	}{
+		{name: "executing failed", executeStatus: http.StatusBadRequest,
+			wantErr: "Error while executing vRealize Automation pipeline", want: restored},
+		{name: "aborted and canceled", executeStatus: http.StatusAccepted, abort: true, cancelOnAbort: true,
+			cancelStatus: http.StatusOK, wantErr: "Aborted", want: restored},
+		{name: "aborted and canceling failed", executeStatus: http.StatusAccepted, abort: true, cancelOnAbort: true,
+			cancelStatus: http.StatusInternalServerError, wantErr: "Aborted", want: leftEnabled},
+		{name: "aborted without canceling", executeStatus: http.StatusAccepted, abort: true,
+			wantErr: "Aborted", want: leftEnabled},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, abort := context.WithCancel(context.Background())
+			defer abort()
+			transport := &fake.Transport{Handle: func(request fake.Request) (int, string) {
+				switch {
+				case request.Path == "/codestream/api/pipelines/p1":
+					return http.StatusOK, `{"id":"p1","name":"deploy","enabled":false,"state":"DISABLED"}`
+				case request.Path == "/codestream/api/pipelines/p1/executions":
+					if test.abort {
+						abort()
+					}
+					return test.executeStatus, `{"executionId":"e1","executionIndex":7}`
+				case request.Path == "/codestream/api/executions/e1/cancel":
+					return test.cancelStatus, `{}`
+				}
+				return http.StatusNotFound, `{}`
+			}}
+			params := OutParams{Wait: true, EnableIfDisabled: true, CancelOnAbort: test.cancelOnAbort}
+
+			_, _, err := triggerPipeline(ctx, newFakeClient(t, transport), "", VRASource{PipelineID: "p1"}, params)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("triggerPipeline() error = %v, want %q", err, test.wantErr)
+			}
+			if got := pipelineStates(t, transport); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("pipeline updates = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"log"
+	"time"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+// progressLogger logs pipeline, stage and task status transitions
+// by diffing successive snapshots of the execution. Durations are
+// measured from the first snapshot where the stage or task started.
+type progressLogger struct {
+	statuses  map[string]vra.ExecutionStatus
+	messages  map[string]string
+	startedAt map[string]time.Time
+	now       func() time.Time
+}
+
+func newProgressLogger() *progressLogger {
+	return &progressLogger{
+		statuses:  make(map[string]vra.ExecutionStatus),
+		messages:  make(map[string]string),
+		startedAt: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// logProgress logs the transitions since the previous snapshot
+func (progress *progressLogger) logProgress(execution vra.PipelineExecution) {
+	if !execution.Status.IsKnown() {
+		log.Printf("vRealize Automation pipeline has unknown status %q, waiting until it reaches a known terminal status", execution.Status)
+	}
+	progress.logTransition("Pipeline", execution.Status, execution.StatusMessage)
+
+	for _, stageName := range execution.StageOrder {
+		stageExec := execution.Stages[stageName]
+		stageLabel := "Stage " + stageName
+		progress.logTransition(stageLabel, stageExec.Status, stageExec.StatusMessage)
+
+		for _, taskName := range stageExec.TaskOrder {
+			taskExec := stageExec.Tasks[taskName]
+			progress.logTransition(stageLabel+" > Task "+taskName, taskExec.Status, taskExec.StatusMessage)
+		}
+	}
+}
+
+// logTransition logs the status change of given label along
+// with its duration once it finishes, and its status message
+func (progress *progressLogger) logTransition(label string, status vra.ExecutionStatus, message string) {
+	previous, seen := progress.statuses[label]
+	now := progress.now()
+	if status != previous {
+		progress.statuses[label] = status
+		if isStarted(status) {
+			if _, ok := progress.startedAt[label]; !ok {
+				progress.startedAt[label] = now
+			}
+		}
+
+		switch {
+		case !seen && status == vra.StatusNotStarted:
+			// Nothing has happened yet
+		case !seen || previous == vra.StatusNotStarted:
+			log.Printf("%s: %s", label, status)
+		case status.IsTerminal():
+			log.Printf("%s: %s → %s (%s)", label, previous, status, now.Sub(progress.startedAt[label]).Round(time.Second))
+		default:
+			log.Printf("%s: %s → %s", label, previous, status)
+		}
+	}
+
+	if message != "" && message != progress.messages[label] {
+		progress.messages[label] = message
+		log.Printf("%s: %s", label, message)
+	}
+}
+
+// isStarted tells whether the execution has started
+func isStarted(status vra.ExecutionStatus) bool {
+	return status != vra.StatusNotStarted && status != vra.StatusQueued
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+)
+
+// captureLogs redirects the log output to the returned buffer during the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	flags, output := log.Flags(), log.Writer()
+	log.SetFlags(0)
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetFlags(flags)
+		log.SetOutput(output)
+	})
+	return &buf
+}
+
+// newSnapshot creates execution with a single stage and task in given status
+func newSnapshot(status vra.ExecutionStatus, message string) vra.PipelineExecution {
+	return vra.PipelineExecution{
+		Status:     status,
+		StageOrder: []string{"Build"},
+		Stages: map[string]vra.PipelineStageExecution{
+			"Build": {
+				Status:    status,
+				TaskOrder: []string{"Compile"},
+				Tasks: map[string]vra.PipelineTaskExecution{
+					"Compile": {Status: status, StatusMessage: message},
+				},
+			},
+		},
+	}
+}
+
+func TestProgressLogger(t *testing.T) {
+	buf := captureLogs(t)
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	progress := newProgressLogger()
+	progress.now = func() time.Time { return now }
+
+	steps := []struct {
+		name     string
+		after    time.Duration
+		snapshot vra.PipelineExecution
+		want     []string
+	}{
+		{"not started is suppressed", 0, newSnapshot(vra.StatusNotStarted, ""), nil},
+		{"started", 10 * time.Second, newSnapshot(vra.StatusRunning, "Compiling"), []string{
+			"Pipeline: RUNNING",
+			"Stage Build: RUNNING",
+			"Stage Build > Task Compile: RUNNING",
+			"Stage Build > Task Compile: Compiling",
+		}},
+		{"unchanged", 30 * time.Second, newSnapshot(vra.StatusRunning, "Compiling"), nil},
+		{"completed", 2*time.Minute + 42*time.Second, newSnapshot(vra.StatusCompleted, "Compiled"), []string{
+			"Pipeline: RUNNING → COMPLETED (3m12s)",
+			"Stage Build: RUNNING → COMPLETED (3m12s)",
+			"Stage Build > Task Compile: RUNNING → COMPLETED (3m12s)",
+			"Stage Build > Task Compile: Compiled",
+		}},
+	}
+	for _, step := range steps {
+		buf.Reset()
+		now = now.Add(step.after)
+		progress.logProgress(step.snapshot)
+
+		var got []string
+		if output := strings.TrimSpace(buf.String()); output != "" {
+			got = strings.Split(output, "\n")
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: logged %q, want %q", step.name, got, step.want)
+		}
+	}
+}
+
+func TestProgressLoggerQueued(t *testing.T) {
+	buf := captureLogs(t)
+	now := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	progress := newProgressLogger()
+	progress.now = func() time.Time { return now }
+
+	progress.logTransition("Pipeline", vra.StatusQueued, "")
+	now = now.Add(time.Minute)
+	progress.logTransition("Pipeline", vra.StatusRunning, "")
+	now = now.Add(5 * time.Second)
+	progress.logTransition("Pipeline", vra.StatusFailed, "")
+
+	// Queued time is not part of the duration
+	want := "Pipeline: QUEUED\nPipeline: QUEUED → RUNNING\nPipeline: RUNNING → FAILED (5s)\n"
+	if buf.String() != want {
+		t.Errorf("logged %q, want %q", buf.String(), want)
+	}
+}
@@ -3,20 +3,56 @@
 
 package resource
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/csp"
+	"github.com/vmware/concourse-vrealize-automation-resource/internal/vra"
+	httpUtils "github.com/vmware/concourse-vrealize-automation-resource/pkg/utils"
+)
+
 // VRASource holds the source configuration
 type VRASource struct {
-	Host     string `json:"host"`
-	Pipeline string `json:"pipeline"`
-	APIToken string `json:"apiToken"`
+	Host       string `json:"host"`
+	Pipeline   string `json:"pipeline"`
+	PipelineID string `json:"pipelineId"`
+	Project    string `json:"project"`
+	APIToken   string `json:"apiToken"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Domain     string `json:"domain"`
+
+	ConnectTimeout  int `json:"connectTimeout"`
+	ResponseTimeout int `json:"responseTimeout"`
+
+	CACert     string `json:"caCert"`
+	ClientCert string `json:"clientCert"`
+	ClientKey  string `json:"clientKey"`
+	Insecure   bool   `json:"insecure"`
+
+	HTTPProxy     string `json:"httpProxy"`
+	HTTPSProxy    string `json:"httpsProxy"`
+	NoProxy       string `json:"noProxy"`
+	ProxyUsername string `json:"proxyUsername"`
+	ProxyPassword string `json:"proxyPassword"`
 }
 
-// VRAVersion holds the version info
+// VRAVersion holds the version info identifying a Code Stream
+// pipeline execution. Concourse versions only take string values.
 type VRAVersion struct {
-	Value string `json:"value"`
+	ExecutionID string `json:"executionId"`
+	Index       string `json:"index"`
+	PipelineID  string `json:"pipelineId"`
 }
 
-// VRAResource holds the resource type configuration
+// VRAResource holds the resource type configuration.
+// Ctx is canceled when Concourse aborts the build.
 type VRAResource struct {
+	Ctx       context.Context
 	Src       *VRASource
 	Ver       *VRAVersion
 	OutParams *OutParams
@@ -24,9 +60,19 @@ type VRAResource struct {
 
 // OutParams holds the out task params
 type OutParams struct {
-	Wait        bool              `json:"wait"`
-	WaitTimeout int               `json:"waitTimeout"`
-	Input       map[string]string `json:"input"`
+	Wait             bool                   `json:"wait"`
+	WaitTimeout      int                    `json:"waitTimeout"`
+	PollInterval     int                    `json:"pollInterval"`
+	MaxPollInterval  int                    `json:"maxPollInterval"`
+	PollMultiplier   float64                `json:"pollMultiplier"`
+	FailOnError      *bool                  `json:"failOnError"`
+	CancelOnAbort    bool                   `json:"cancelOnAbort"`
+	Input            map[string]interface{} `json:"input"`
+	InputFile        string                 `json:"inputFile"`
+	InputFrom        map[string]string      `json:"inputFrom"`
+	Comments         string                 `json:"comments"`
+	StrictInputs     *bool                  `json:"strictInputs"`
+	EnableIfDisabled bool                   `json:"enableIfDisabled"`
 }
 
 type MetadataField struct {
@@ -44,12 +90,102 @@ func (r *VRAResource) Version() interface{} {
 	return r.Ver
 }
 
-// Params returns pointer to the Out params definition struct
+// Check returns the new versions of the resource
+func (r *VRAResource) Check() (version interface{}, err error) {
+	return check(r.context(), *r.Src, *r.Ver)
+}
+
+// Params returns pointer to the params definition struct
 func (r *VRAResource) Params() (params interface{}) {
 	return r.OutParams
 }
 
+// In fetches the pipeline execution of the given version
+// and returns the version and metadata
+func (r *VRAResource) In(dir string) (version interface{}, metadata []interface{}, err error) {
+	return in(r.context(), dir, *r.Src, *r.Ver)
+}
+
 // Out Puts the resource and returns the new version and metadata
 func (r *VRAResource) Out(dir string) (version interface{}, metadata []interface{}, err error) {
-	return out(*r.Src, *r.OutParams)
-}
\ No newline at end of file
+	return out(r.context(), dir, *r.Src, *r.OutParams)
+}
+
+// context returns the resource context, which
+// defaults to background context if not set
+func (r *VRAResource) context() context.Context {
+	if r.Ctx == nil {
+		return context.Background()
+	}
+	return r.Ctx
+}
+
+// newVersion creates the version for given pipeline execution
+func newVersion(pipelineID string, executionID string, index int) VRAVersion {
+	return VRAVersion{ExecutionID: executionID, Index: strconv.Itoa(index), PipelineID: pipelineID}
+}
+
+// newCodeStreamClient creates Code Stream client for the
+// endpoints resolved from the source host
+func newCodeStreamClient(source VRASource) (*vra.Client, error) {
+	endpoints, err := vra.ResolveEndpoints(source.Host)
+	if err != nil {
+		return nil, fmt.Errorf("Error while resolving vRealize Automation endpoints:%w", err)
+	}
+	authenticator, err := newAuthenticator(source, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := httpUtils.NewClient(httpUtils.ClientOptions{
+		Timeouts: httpUtils.Timeouts{
+			Connect:  time.Second * time.Duration(source.ConnectTimeout),
+			Response: time.Second * time.Duration(source.ResponseTimeout),
+		},
+		TLS: httpUtils.TLSOptions{
+			CACert:     source.CACert,
+			ClientCert: source.ClientCert,
+			ClientKey:  source.ClientKey,
+			Insecure:   source.Insecure,
+		},
+		Proxy: httpUtils.ProxyOptions{
+			HTTPProxy:  source.HTTPProxy,
+			HTTPSProxy: source.HTTPSProxy,
+			NoProxy:    source.NoProxy,
+			Username:   source.ProxyUsername,
+			Password:   source.ProxyPassword,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error while configuring HTTP client:%w", err)
+	}
+	return vra.New(httpClient, endpoints.VRAAPIBaseURL, csp.New(httpClient, authenticator)), nil
+}
+
+// newAuthenticator picks the authentication strategy from the source.
+// API token takes precedence over username and password.
+func newAuthenticator(source VRASource, endpoints vra.Endpoints) (csp.Authenticator, error) {
+	if source.APIToken != "" {
+		return csp.NewRefreshTokenAuthenticator(endpoints.CSPAPIBaseURL, source.APIToken), nil
+	}
+	if source.Username != "" && source.Password != "" {
+		return csp.NewPasswordAuthenticator(endpoints.CSPAPIBaseURL, endpoints.VRAAPIBaseURL,
+			source.Username, source.Password, source.Domain), nil
+	}
+	return nil, errors.New("Either apiToken or username and password must be provided")
+}
+
+// getPipelineID returns the configured pipeline ID, or looks it
+// up from the pipeline name within the configured project
+func getPipelineID(ctx context.Context, csClient *vra.Client, source VRASource) (string, error) {
+	if source.PipelineID != "" {
+		return source.PipelineID, nil
+	}
+	if source.Pipeline == "" {
+		return "", errors.New("Either pipeline or pipelineId must be provided")
+	}
+	pipelineID, err := csClient.GetPipelineIDFromName(ctx, source.Pipeline, source.Project)
+	if err != nil {
+		return "", fmt.Errorf("Error while getting pipeline ID from name:%w", err)
+	}
+	return pipelineID, nil
+}
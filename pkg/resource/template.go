@@ -0,0 +1,76 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"regexp"
+
+	"github.com/tbe/resource-framework/resource/template"
+)
+
+// defaultComments names the Concourse build, followed
+// by its URL to link the execution back to the build
+const defaultComments = "Triggered by Concourse CI build ${BUILD_PIPELINE_NAME}/${BUILD_JOB_NAME} #${BUILD_NAME}: "
+
+// buildVariablePattern matches $NAME and ${NAME} references
+var buildVariablePattern = regexp.MustCompile(`\$(\{\w+\}|\w+)`)
+
+// buildVariables holds the Concourse build metadata
+// which can be referenced in inputs and comments
+var buildVariables = map[string]bool{
+	"BUILD_ID":            true,
+	"BUILD_NAME":          true,
+	"BUILD_JOB_NAME":      true,
+	"BUILD_PIPELINE_NAME": true,
+	"BUILD_TEAM_NAME":     true,
+	"ATC_EXTERNAL_URL":    true,
+}
+
+// interpolate replaces the references to Concourse build metadata.
+// Any other reference is left as it is, so that values containing
+// $ are passed unchanged.
+func interpolate(value string) string {
+	return buildVariablePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		name := buildVariablePattern.FindStringSubmatch(reference)[1]
+		if name[0] == '{' {
+			name = name[1 : len(name)-1]
+		}
+		if !buildVariables[name] {
+			return reference
+		}
+		return template.ShellExpand("${" + name + "}")
+	})
+}
+
+// interpolateValue interpolates every string within the
+// value, including the ones nested in lists and objects
+func interpolateValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case string:
+		return interpolate(typedValue)
+	case []interface{}:
+		interpolated := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			interpolated[i] = interpolateValue(item)
+		}
+		return interpolated
+	case map[string]interface{}:
+		interpolated := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			interpolated[key] = interpolateValue(item)
+		}
+		return interpolated
+	default:
+		return value
+	}
+}
+
+// executionComments returns the interpolated comments
+// param, or the default comments if not provided
+func executionComments(params OutParams) string {
+	if params.Comments == "" {
+		return interpolate(defaultComments) + template.BuildURL()
+	}
+	return interpolate(params.Comments)
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// setBuildEnv sets Concourse build metadata for the test
+func setBuildEnv(t *testing.T) {
+	t.Helper()
+	env := map[string]string{
+		"BUILD_ID":            "1234",
+		"BUILD_NAME":          "42",
+		"BUILD_JOB_NAME":      "deploy",
+		"BUILD_PIPELINE_NAME": "app",
+		"BUILD_TEAM_NAME":     "main",
+		"ATC_EXTERNAL_URL":    "https://ci.example.com",
+	}
+	for key, value := range env {
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatalf("Error while setting %s: %v", key, err)
+		}
+	}
+	t.Cleanup(func() {
+		for key := range env {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestInterpolate(t *testing.T) {
+	setBuildEnv(t)
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"no reference", "plain value", "plain value"},
+		{"reference", "build $BUILD_NAME", "build 42"},
+		{"braced reference", "${BUILD_PIPELINE_NAME}-${BUILD_JOB_NAME}", "app-deploy"},
+		{"all references", "$BUILD_ID $BUILD_NAME $BUILD_JOB_NAME $BUILD_PIPELINE_NAME $BUILD_TEAM_NAME $ATC_EXTERNAL_URL",
+			"1234 42 deploy app main https://ci.example.com"},
+		{"unknown reference kept", "$HOME and ${PATH}", "$HOME and ${PATH}"},
+		{"dollar amount kept", "costs $5", "costs $5"},
+		{"lone dollar kept", "$ and ${", "$ and ${"},
+		{"longer name kept", "$BUILD_NAME_SUFFIX", "$BUILD_NAME_SUFFIX"},
+		{"braces end name", "${BUILD_NAME}_SUFFIX", "42_SUFFIX"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := interpolate(test.value); got != test.want {
+				t.Errorf("interpolate(%q) = %q, want %q", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateValue(t *testing.T) {
+	setBuildEnv(t)
+	value := map[string]interface{}{
+		"name":    "$BUILD_JOB_NAME",
+		"enabled": true,
+		"count":   float64(3),
+		"tags":    []interface{}{"build-$BUILD_NAME", 1.5, nil},
+		"nested":  map[string]interface{}{"url": "$ATC_EXTERNAL_URL"},
+	}
+	want := map[string]interface{}{
+		"name":    "deploy",
+		"enabled": true,
+		"count":   float64(3),
+		"tags":    []interface{}{"build-42", 1.5, nil},
+		"nested":  map[string]interface{}{"url": "https://ci.example.com"},
+	}
+	if got := interpolateValue(value); !reflect.DeepEqual(got, want) {
+		t.Errorf("interpolateValue() = %#v, want %#v", got, want)
+	}
+	if value["name"] != "$BUILD_JOB_NAME" {
+		t.Errorf("interpolateValue() modified its argument")
+	}
+}
+
+func TestExecutionComments(t *testing.T) {
+	setBuildEnv(t)
+	tests := []struct {
+		name     string
+		comments string
+		want     string
+	}{
+		{"default", "", "Triggered by Concourse CI build app/deploy #42: https://ci.example.com/teams/main/pipelines/app/jobs/deploy/builds/42"},
+		{"provided", "Release $BUILD_NAME", "Release 42"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := executionComments(OutParams{Comments: test.comments}); got != test.want {
+				t.Errorf("executionComments() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,458 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The package level functions are kept for existing importers and
+// are thin wrappers over Client. They share a transport built from
+// the environment, so that connections are reused across the calls.
+var (
+	defaultTransport     *http.Transport
+	defaultTransportErr  error
+	defaultTransportOnce sync.Once
+)
+
+// defaultClient creates client on the shared transport with given
+// retry options. Non-positive retry count disables the retries.
+func defaultClient(retryCount int, retryWaitSeconds time.Duration) (*Client, error) {
+	defaultTransportOnce.Do(func() {
+		defaultTransport, defaultTransportErr = newTransport(Timeouts{}, TLSOptions{}, ProxyOptions{})
+	})
+	if defaultTransportErr != nil {
+		return nil, defaultTransportErr
+	}
+	if retryCount <= 0 {
+		retryCount = -1
+	}
+	return NewClient(ClientOptions{Transport: defaultTransport, RetryCount: retryCount, RetryWait: retryWaitSeconds})
+}
+
+// withAuthorization returns copy of the headers with Authorization header
+func withAuthorization(headers map[string]string, authorization string) map[string]string {
+	authHeaders := make(map[string]string, len(headers)+1)
+	for key, value := range headers {
+		authHeaders[key] = value
+	}
+	authHeaders["Authorization"] = authorization
+	return authHeaders
+}
+
+func basicAuthorization(username string, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func bearerAuthorization(token string) string {
+	return "Bearer " + token
+}
+
+// Get makes an HTTP call to given URL and returns Response
+//
+// Deprecated: Use Client.GetHeaders instead.
+func Get(URL string) (Response, error) {
+	return GetHeadersCustomRetry(URL, nil, -1, -1)
+}
+
+// GetRetry makes an HTTP call to given URL and returns Response.
+// It also retries for failures.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetRetry(URL string) (Response, error) {
+	return GetHeadersCustomRetry(URL, nil, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// GetCustomRetry makes an HTTP call to given URL and returns Response.
+// It also retries for failures with given retry count and wait seconds.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetCustomRetry(URL string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return GetHeadersCustomRetry(URL, nil, retryCount, retryWaitSeconds)
+}
+
+// GetHeaders makes an HTTP call to given URL with headers
+// and returns response.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeaders(URL string, headers map[string]string) (Response, error) {
+	return GetHeadersCustomRetry(URL, headers, -1, -1)
+}
+
+// GetHeadersRetry makes an HTTP call to given URL with headers
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersRetry(URL string, headers map[string]string) (Response, error) {
+	return GetHeadersCustomRetry(URL, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// GetHeadersCustomRetry makes an HTTP call to given URL with headers
+// and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersCustomRetry(URL string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return GetHeadersCustomRetryContext(context.Background(), URL, headers, retryCount, retryWaitSeconds)
+}
+
+// GetHeadersRetryContext makes an HTTP call to given URL with headers
+// and returns response. It also retries for failures. The call is
+// aborted when given context is done.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersRetryContext(ctx context.Context, URL string, headers map[string]string) (Response, error) {
+	return GetHeadersCustomRetryContext(ctx, URL, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// GetHeadersCustomRetryContext makes an HTTP call to given URL with headers
+// and returns response. It also retries for failures with given retry
+// count and wait seconds. The call is aborted when given context is done.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersCustomRetryContext(ctx context.Context, URL string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	client, err := defaultClient(retryCount, retryWaitSeconds)
+	if err != nil {
+		return Response{}, err
+	}
+	return client.GetHeaders(ctx, URL, headers)
+}
+
+// GetBasicAuth makes an HTTP call to given URL with basic authentication
+// and returns response.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetBasicAuth(URL string, username string, password string) (Response, error) {
+	return GetBasicAuthCustomRetry(URL, username, password, -1, -1)
+}
+
+// GetBasicAuthRetry makes an HTTP call to given URL with basic authentication
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetBasicAuthRetry(URL string, username string, password string) (Response, error) {
+	return GetBasicAuthCustomRetry(URL, username, password, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// GetBasicAuthCustomRetry makes an HTTP call to given URL with basic authentication
+// and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetBasicAuthCustomRetry(URL string, username string, password string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return GetHeadersCustomRetry(URL, withAuthorization(nil, basicAuthorization(username, password)), retryCount, retryWaitSeconds)
+}
+
+// GetCustomAuth makes an HTTP call to given URL with custom authentication
+// and returns response.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetCustomAuth(URL string, token string) (Response, error) {
+	return GetCustomAuthCustomRetry(URL, token, -1, -1)
+}
+
+// GetCustomAuthRetry makes an HTTP call to given URL with custom authentication
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetCustomAuthRetry(URL string, token string) (Response, error) {
+	return GetCustomAuthCustomRetry(URL, token, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// GetCustomAuthCustomRetry makes an HTTP call to given URL with custom authentication
+// and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetCustomAuthCustomRetry(URL string, token string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return GetHeadersCustomAuthCustomRetry(URL, nil, retryCount, retryWaitSeconds, token)
+}
+
+// GetHeadersCustomAuth makes an HTTP call to given URL with headers and custom auth
+// and returns response.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersCustomAuth(URL string, headers map[string]string, token string) (Response, error) {
+	return GetHeadersCustomAuthCustomRetry(URL, headers, -1, -1, token)
+}
+
+// GetHeadersCustomAuthRetry makes an HTTP call to given URL with headers and custom auth
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersCustomAuthRetry(URL string, headers map[string]string, token string) (Response, error) {
+	return GetHeadersCustomAuthCustomRetry(URL, headers, defaultRetryCount, defaultRetryWaitSeconds, token)
+}
+
+// GetHeadersCustomAuthCustomRetry makes an HTTP call to given URL with headers and custom
+// auth and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.GetHeaders instead.
+func GetHeadersCustomAuthCustomRetry(URL string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration, token string) (Response, error) {
+	return GetHeadersCustomRetry(URL, withAuthorization(headers, bearerAuthorization(token)), retryCount, retryWaitSeconds)
+}
+
+// Post makes an HTTP call to given URL and returns response.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func Post(URL string, requestBody string) (Response, error) {
+	return PostHeadersCustomRetry(URL, requestBody, nil, -1, -1)
+}
+
+// PostHeaders makes an HTTP call to given URL with headers
+// and returns response.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostHeaders(URL string, requestBody string, headers map[string]string) (Response, error) {
+	return PostHeadersCustomRetry(URL, requestBody, headers, -1, -1)
+}
+
+// PostHeadersRetry makes an HTTP call to given URL with headers
+// and returns response. It is retried only when it never
+// reached the server or was rejected with 429.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostHeadersRetry(URL string, requestBody string, headers map[string]string) (Response, error) {
+	return PostHeadersCustomRetry(URL, requestBody, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PostHeadersCustomRetry makes an HTTP call to given URL with headers
+// and returns response. It is retried only when it never reached the
+// server or was rejected with 429, with given retry count and wait
+// seconds.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostHeadersCustomRetry(URL string, requestBody string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PostHeadersCustomRetryContext(context.Background(), URL, requestBody, headers, retryCount, retryWaitSeconds)
+}
+
+// PostHeadersRetryContext makes an HTTP call to given URL with headers
+// and returns response. It is retried only when it never
+// reached the server or was rejected with 429. The call is
+// aborted when given context is done.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostHeadersRetryContext(ctx context.Context, URL string, requestBody string, headers map[string]string) (Response, error) {
+	return PostHeadersCustomRetryContext(ctx, URL, requestBody, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PostHeadersCustomRetryContext makes an HTTP call to given URL with headers
+// and returns response. It is retried only when it never reached the
+// server or was rejected with 429, with given retry count and wait
+// seconds. The call is aborted when given context is done.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostHeadersCustomRetryContext(ctx context.Context, URL string, requestBody string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	client, err := defaultClient(retryCount, retryWaitSeconds)
+	if err != nil {
+		return Response{}, err
+	}
+	return client.PostHeaders(ctx, URL, requestBody, headers)
+}
+
+// PostHeadersFormDataRetry makes an HTTP call to given URL with form data,
+// headers and returns response. It is retried only when it
+// never reached the server or was rejected with 429.
+//
+// Deprecated: Use Client.PostHeadersFormData instead.
+func PostHeadersFormDataRetry(URL string, formData map[string]string, headers map[string]string) (Response, error) {
+	return PostHeadersFormDataCustomRetry(URL, formData, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PostHeadersFormDataCustomRetry makes an HTTP call to given URL with form data,
+// headers and returns response. It is retried only when it never reached
+// the server or was rejected with 429, with given retry count and wait
+// seconds.
+//
+// Deprecated: Use Client.PostHeadersFormData instead.
+func PostHeadersFormDataCustomRetry(URL string, formData map[string]string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PostHeadersFormDataCustomRetryContext(context.Background(), URL, formData, headers, retryCount, retryWaitSeconds)
+}
+
+// PostHeadersFormDataRetryContext makes an HTTP call to given URL with form data,
+// headers and returns response. It is retried only when it
+// never reached the server or was rejected with 429. The call is
+// aborted when given context is done.
+//
+// Deprecated: Use Client.PostHeadersFormData instead.
+func PostHeadersFormDataRetryContext(ctx context.Context, URL string, formData map[string]string, headers map[string]string) (Response, error) {
+	return PostHeadersFormDataCustomRetryContext(ctx, URL, formData, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PostHeadersFormDataCustomRetryContext makes an HTTP call to given URL with form
+// data, headers and returns response. It is retried only when it never reached
+// the server or was rejected with 429, with given retry count and wait
+// seconds. The call is aborted when given context is done.
+//
+// Deprecated: Use Client.PostHeadersFormData instead.
+func PostHeadersFormDataCustomRetryContext(ctx context.Context, URL string, formData map[string]string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	client, err := defaultClient(retryCount, retryWaitSeconds)
+	if err != nil {
+		return Response{}, err
+	}
+	return client.PostHeadersFormData(ctx, URL, formData, headers)
+}
+
+// PostBasicAuth makes an HTTP call to given URL with basic authentication
+// and returns response.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostBasicAuth(URL string, requestBody string, username string, password string) (Response, error) {
+	return PostBasicAuthCustomRetry(URL, requestBody, username, password, -1, -1)
+}
+
+// PostBasicAuthRetry makes an HTTP call to given URL with basic authentication
+// and returns response. It is retried only when it never
+// reached the server or was rejected with 429.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostBasicAuthRetry(URL string, requestBody string, username string, password string) (Response, error) {
+	return PostBasicAuthCustomRetry(URL, requestBody, username, password, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PostBasicAuthCustomRetry makes an HTTP call to given URL with basic authentication
+// and returns response. It is retried only when it never reached the
+// server or was rejected with 429, with given retry count and wait
+// seconds.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostBasicAuthCustomRetry(URL string, requestBody string, username string, password string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PostHeadersCustomRetry(URL, requestBody, withAuthorization(nil, basicAuthorization(username, password)), retryCount, retryWaitSeconds)
+}
+
+// PostCustomAuth makes an HTTP call to given URL with custom authentication
+// and returns response.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostCustomAuth(URL string, requestBody string, token string) (Response, error) {
+	return PostCustomAuthCustomRetry(URL, requestBody, token, -1, -1)
+}
+
+// PostCustomAuthRetry makes an HTTP call to given URL with custom authentication
+// and returns response. It is retried only when it never
+// reached the server or was rejected with 429.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostCustomAuthRetry(URL string, requestBody string, token string) (Response, error) {
+	return PostCustomAuthCustomRetry(URL, requestBody, token, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PostCustomAuthCustomRetry makes an HTTP call to given URL with custom authentication
+// and returns response. It is retried only when it never reached the
+// server or was rejected with 429, with given retry count and wait
+// seconds.
+//
+// Deprecated: Use Client.PostHeaders instead.
+func PostCustomAuthCustomRetry(URL string, requestBody string, token string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PostHeadersCustomRetry(URL, requestBody, withAuthorization(nil, bearerAuthorization(token)), retryCount, retryWaitSeconds)
+}
+
+// Put makes PUT HTTP call to given URL and returns response.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func Put(URL string, requestBody string) (Response, error) {
+	return PutHeadersCustomRetry(URL, requestBody, nil, -1, -1)
+}
+
+// PutHeaders makes PUT HTTP call to given URL with headers
+// and returns response.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutHeaders(URL string, requestBody string, headers map[string]string) (Response, error) {
+	return PutHeadersCustomRetry(URL, requestBody, headers, -1, -1)
+}
+
+// PutHeadersRetry makes PUT HTTP call to given URL with headers
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutHeadersRetry(URL string, requestBody string, headers map[string]string) (Response, error) {
+	return PutHeadersCustomRetry(URL, requestBody, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PutHeadersCustomRetry makes PUT HTTP call to given URL with headers
+// and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutHeadersCustomRetry(URL string, requestBody string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PutHeadersCustomRetryContext(context.Background(), URL, requestBody, headers, retryCount, retryWaitSeconds)
+}
+
+// PutHeadersRetryContext makes PUT HTTP call to given URL with headers
+// and returns response. It also retries for failures. The call is
+// aborted when given context is done.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutHeadersRetryContext(ctx context.Context, URL string, requestBody string, headers map[string]string) (Response, error) {
+	return PutHeadersCustomRetryContext(ctx, URL, requestBody, headers, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PutHeadersCustomRetryContext makes PUT HTTP call to given URL with headers
+// and returns response. It also retries for failures with given retry
+// count and wait seconds. The call is aborted when given context is done.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutHeadersCustomRetryContext(ctx context.Context, URL string, requestBody string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	client, err := defaultClient(retryCount, retryWaitSeconds)
+	if err != nil {
+		return Response{}, err
+	}
+	return client.PutHeaders(ctx, URL, requestBody, headers)
+}
+
+// PutBasicAuth makes PUT HTTP call to given URL with basic authentication
+// and returns response.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutBasicAuth(URL string, requestBody string, username string, password string) (Response, error) {
+	return PutBasicAuthCustomRetry(URL, requestBody, username, password, -1, -1)
+}
+
+// PutBasicAuthRetry makes PUT HTTP call to given URL with basic authentication
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutBasicAuthRetry(URL string, requestBody string, username string, password string) (Response, error) {
+	return PutBasicAuthCustomRetry(URL, requestBody, username, password, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PutBasicAuthCustomRetry makes PUT HTTP call to given URL with basic authentication
+// and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutBasicAuthCustomRetry(URL string, requestBody string, username string, password string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PutHeadersCustomRetry(URL, requestBody, withAuthorization(nil, basicAuthorization(username, password)), retryCount, retryWaitSeconds)
+}
+
+// PutCustomAuth makes PUT HTTP call to given URL with custom authentication
+// and returns response.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutCustomAuth(URL string, requestBody string, token string) (Response, error) {
+	return PutCustomAuthCustomRetry(URL, requestBody, token, -1, -1)
+}
+
+// PutCustomAuthRetry makes PUT HTTP call to given URL with custom authentication
+// and returns response. It also retries for failures.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutCustomAuthRetry(URL string, requestBody string, token string) (Response, error) {
+	return PutCustomAuthCustomRetry(URL, requestBody, token, defaultRetryCount, defaultRetryWaitSeconds)
+}
+
+// PutCustomAuthCustomRetry makes PUT HTTP call to given URL with custom authentication
+// and returns response. It also retries for failures with given retry
+// count and wait seconds.
+//
+// Deprecated: Use Client.PutHeaders instead.
+func PutCustomAuthCustomRetry(URL string, requestBody string, token string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
+	return PutHeadersCustomRetry(URL, requestBody, withAuthorization(nil, bearerAuthorization(token)), retryCount, retryWaitSeconds)
+}
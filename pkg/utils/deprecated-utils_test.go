@@ -0,0 +1,58 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeprecatedFunctions(t *testing.T) {
+	var gotMethod, gotAuth, gotHeader, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		gotMethod, gotAuth, gotHeader, gotBody = request.Method, request.Header.Get("Authorization"), request.Header.Get("X-Test"), string(body)
+		writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		call       func() (Response, error)
+		wantMethod string
+		wantAuth   string
+		wantHeader string
+		wantBody   string
+	}{
+		{"Get", func() (Response, error) { return Get(server.URL) }, http.MethodGet, "", "", ""},
+		{"GetHeadersRetry", func() (Response, error) { return GetHeadersRetry(server.URL, map[string]string{"X-Test": "1"}) },
+			http.MethodGet, "", "1", ""},
+		{"GetBasicAuth", func() (Response, error) { return GetBasicAuth(server.URL, "user", "pass") },
+			http.MethodGet, "Basic dXNlcjpwYXNz", "", ""},
+		{"GetHeadersCustomAuth", func() (Response, error) {
+			return GetHeadersCustomAuth(server.URL, map[string]string{"X-Test": "2"}, "token")
+		}, http.MethodGet, "Bearer token", "2", ""},
+		{"PostHeadersRetry", func() (Response, error) { return PostHeadersRetry(server.URL, "body", nil) },
+			http.MethodPost, "", "", "body"},
+		{"PostHeadersFormDataRetry", func() (Response, error) {
+			return PostHeadersFormDataRetry(server.URL, map[string]string{"a": "b"}, nil)
+		}, http.MethodPost, "", "", "a=b"},
+		{"PutCustomAuth", func() (Response, error) { return PutCustomAuth(server.URL, "body", "token") },
+			http.MethodPut, "Bearer token", "", "body"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := test.call()
+			if err != nil || response.Code != http.StatusOK || response.ResponseString != "ok" {
+				t.Fatalf("%s() = %+v, %v, want 200 ok", test.name, response, err)
+			}
+			if gotMethod != test.wantMethod || gotAuth != test.wantAuth || gotHeader != test.wantHeader || gotBody != test.wantBody {
+				t.Errorf("%s() sent %s, auth %q, header %q, body %q, want %s, auth %q, header %q, body %q", test.name,
+					gotMethod, gotAuth, gotHeader, gotBody, test.wantMethod, test.wantAuth, test.wantHeader, test.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Headers carrying the request ID, if not part of the error body
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Vmware-Request-Id"}
+
+// APIError holds the details of a request which got
+// unexpected status code, including the error returned
+// by vRealize Automation or CSP in the response body
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	ErrorCode  string
+	Message    string
+	RequestID  string
+	// RetryAfter is the wait asked by the server, if any
+	RetryAfter time.Duration
+}
+
+// errorBody holds the error fields returned by
+// vRealize Automation and CSP APIs
+type errorBody struct {
+	Message       string          `json:"message"`
+	ErrorCode     json.RawMessage `json:"errorCode"`
+	ServerErrorID string          `json:"serverErrorId"`
+	RequestID     string          `json:"requestId"`
+	Error         string          `json:"error"`
+	Description   string          `json:"error_description"`
+}
+
+func (err *APIError) Error() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "%s %s returned %s", err.Method, err.URL, err.Status)
+	if err.Message != "" {
+		fmt.Fprintf(&message, ": %s", err.Message)
+	}
+	if err.ErrorCode != "" {
+		fmt.Fprintf(&message, " (error code: %s)", err.ErrorCode)
+	}
+	if err.RequestID != "" {
+		fmt.Fprintf(&message, " (request ID: %s)", err.RequestID)
+	}
+	return message.String()
+}
+
+// NewAPIError creates API error pointer from given response
+func NewAPIError(response Response) *APIError {
+	apiError := &APIError{
+		StatusCode: response.Code,
+		Status:     response.Message,
+		Method:     response.Method,
+		URL:        response.URL,
+		RetryAfter: ParseRetryAfter(response.Headers),
+	}
+	if apiError.Status == "" {
+		apiError.Status = fmt.Sprintf("%d", response.Code)
+	}
+
+	// Error body is best effort, as not every error has it
+	var body errorBody
+	if json.Unmarshal([]byte(response.ResponseString), &body) == nil {
+		apiError.Message = body.Message
+		if apiError.Message == "" {
+			apiError.Message = body.Description
+		}
+		if apiError.Message == "" {
+			apiError.Message = body.Error
+		}
+		apiError.ErrorCode = strings.Trim(string(body.ErrorCode), `"`)
+		apiError.RequestID = body.ServerErrorID
+		if apiError.RequestID == "" {
+			apiError.RequestID = body.RequestID
+		}
+	}
+	for _, header := range requestIDHeaders {
+		if apiError.RequestID != "" {
+			break
+		}
+		apiError.RequestID = response.Headers.Get(header)
+	}
+	return apiError
+}
+
+// CheckResponse returns the request error, or API error if the
+// response does not have any of the expected status codes
+func CheckResponse(response Response, err error, expectedCodes ...int) error {
+	if err != nil {
+		return err
+	}
+	for _, expectedCode := range expectedCodes {
+		if response.Code == expectedCode {
+			return nil
+		}
+	}
+	return NewAPIError(response)
+}
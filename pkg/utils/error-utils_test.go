@@ -0,0 +1,124 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		headers   http.Header
+		message   string
+		errorCode string
+		requestID string
+	}{
+		{
+			name:      "vRA error",
+			body:      `{"message":"Pipeline not found","errorCode":1001,"serverErrorId":"abc-123"}`,
+			message:   "Pipeline not found",
+			errorCode: "1001",
+			requestID: "abc-123",
+		},
+		{
+			name:      "string error code",
+			body:      `{"message":"Forbidden","errorCode":"NOT_ALLOWED","requestId":"def-456"}`,
+			message:   "Forbidden",
+			errorCode: "NOT_ALLOWED",
+			requestID: "def-456",
+		},
+		{
+			name:    "CSP error",
+			body:    `{"error":"invalid_grant","error_description":"Refresh token is invalid"}`,
+			message: "Refresh token is invalid",
+		},
+		{
+			name:    "error without description",
+			body:    `{"error":"invalid_grant"}`,
+			message: "invalid_grant",
+		},
+		{
+			name:      "request ID from header",
+			body:      `{"message":"Internal error"}`,
+			headers:   http.Header{"X-Request-Id": []string{"ghi-789"}},
+			message:   "Internal error",
+			requestID: "ghi-789",
+		},
+		{
+			name:      "request ID of body preferred",
+			body:      `{"serverErrorId":"abc-123"}`,
+			headers:   http.Header{"X-Request-Id": []string{"ghi-789"}},
+			requestID: "abc-123",
+		},
+		{
+			name:      "no error body",
+			body:      "<html>Bad Gateway</html>",
+			headers:   http.Header{"X-Vmware-Request-Id": []string{"jkl-012"}},
+			requestID: "jkl-012",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiError := NewAPIError(Response{
+				Code:           http.StatusInternalServerError,
+				ResponseString: tt.body,
+				Headers:        tt.headers,
+				Method:         http.MethodGet,
+				URL:            "https://vra.example.com/codestream/api/pipelines",
+			})
+			if apiError.Message != tt.message {
+				t.Errorf("Message = %q, want %q", apiError.Message, tt.message)
+			}
+			if apiError.ErrorCode != tt.errorCode {
+				t.Errorf("ErrorCode = %q, want %q", apiError.ErrorCode, tt.errorCode)
+			}
+			if apiError.RequestID != tt.requestID {
+				t.Errorf("RequestID = %q, want %q", apiError.RequestID, tt.requestID)
+			}
+			if apiError.Status != "500" {
+				t.Errorf("Status = %q, want status code when message is missing", apiError.Status)
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	apiError := NewAPIError(Response{
+		Code:           http.StatusTooManyRequests,
+		Message:        "429 Too Many Requests",
+		ResponseString: `{"message":"Slow down","errorCode":42,"serverErrorId":"abc-123"}`,
+		Headers:        http.Header{"Retry-After": []string{"5"}},
+		Method:         http.MethodPost,
+		URL:            "https://vra.example.com/codestream/api/pipelines/p1/executions",
+	})
+
+	want := "POST https://vra.example.com/codestream/api/pipelines/p1/executions returned 429 Too Many Requests: Slow down (error code: 42) (request ID: abc-123)"
+	if apiError.Error() != want {
+		t.Errorf("Error() = %q, want %q", apiError.Error(), want)
+	}
+	if apiError.RetryAfter != 5*time.Second {
+		t.Errorf("RetryAfter = %v, want 5s", apiError.RetryAfter)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	requestErr := errors.New("connection refused")
+	if err := CheckResponse(Response{}, requestErr, http.StatusOK); err != requestErr {
+		t.Errorf("CheckResponse() = %v, want request error", err)
+	}
+	if err := CheckResponse(Response{Code: http.StatusCreated}, nil, http.StatusOK, http.StatusCreated); err != nil {
+		t.Errorf("CheckResponse() = %v, want nil for expected code", err)
+	}
+
+	err := CheckResponse(Response{Code: http.StatusNotFound}, nil, http.StatusOK)
+	var apiError *APIError
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusNotFound {
+		t.Errorf("CheckResponse() = %v, want API error with status code 404", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+// ClientOptions holds the configuration of HTTP client.
+// Zero values fall back to the defaults.
+type ClientOptions struct {
+	Timeouts Timeouts
+	TLS      TLSOptions
+	Proxy    ProxyOptions
+	// RetryCount is the number of retries for failures.
+	// Negative value disables the retries.
+	RetryCount int
+	RetryWait  time.Duration
+	// Headers are sent with every request
+	Headers map[string]string
+	// Transport replaces the transport built from
+	// the other options, e.g. to swap in a fake
+	Transport http.RoundTripper
+}
+
+// Client makes HTTP calls with its own retry policy, transport
+// and default headers. It is safe for concurrent use.
+type Client struct {
+	restyClient *resty.Client
+}
+
+// NewClient creates HTTP client pointer with given options
+func NewClient(options ClientOptions) (*Client, error) {
+	transport := options.Transport
+	if transport == nil {
+		customTransport, err := newTransport(options.Timeouts, options.TLS, options.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport = customTransport
+	}
+	if options.RetryCount == 0 {
+		options.RetryCount = defaultRetryCount
+	}
+	if options.RetryWait <= 0 {
+		options.RetryWait = defaultRetryWaitSeconds
+	}
+
+	restyClient := getNewRestyClient(transport, options.RetryCount, options.RetryWait)
+	restyClient.SetHeaders(options.Headers)
+	return &Client{restyClient: restyClient}, nil
+}
+
+// GetHeaders makes an HTTP call to given URL with headers
+// and returns response. It also retries for failures.
+func (client *Client) GetHeaders(ctx context.Context, URL string, headers map[string]string) (Response, error) {
+	resp, err := client.restyClient.R().
+		SetContext(ctx).
+		SetHeaders(headers).
+		Get(URL)
+	return processResponse(resp), err
+}
+
+// PostHeaders makes an HTTP call to given URL with headers and
+// returns response. As POST is not idempotent, it is retried only
+// when it never reached the server or was rejected with 429.
+func (client *Client) PostHeaders(ctx context.Context, URL string, requestBody string, headers map[string]string) (Response, error) {
+	request := client.restyClient.R()
+	if requestBody != "" {
+		request.
+			SetBody(requestBody)
+	}
+	resp, err := request.
+		SetContext(ctx).
+		SetHeaders(headers).
+		Post(URL)
+	return processResponse(resp), err
+}
+
+// PostHeadersFormData makes an HTTP call to given URL with form data,
+// headers and returns response. As POST is not idempotent, it is retried
+// only when it never reached the server or was rejected with 429.
+func (client *Client) PostHeadersFormData(ctx context.Context, URL string, formData map[string]string, headers map[string]string) (Response, error) {
+	request := client.restyClient.R()
+	if formData != nil {
+		request.
+			SetFormData(formData)
+	}
+	resp, err := request.
+		SetContext(ctx).
+		SetHeaders(headers).
+		Post(URL)
+	return processResponse(resp), err
+}
+
+// PutHeaders makes PUT HTTP call to given URL with headers
+// and returns response. It also retries for failures.
+func (client *Client) PutHeaders(ctx context.Context, URL string, requestBody string, headers map[string]string) (Response, error) {
+	request := client.restyClient.R()
+	if requestBody != "" {
+		request.
+			SetBody(requestBody)
+	}
+	resp, err := request.
+		SetContext(ctx).
+		SetHeaders(headers).
+		Put(URL)
+	return processResponse(resp), err
+}
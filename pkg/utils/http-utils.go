@@ -19,6 +19,16 @@ type Response struct {
 	Message        string
 	ResponseString string
 	Headers        http.Header
+	Method         string
+	URL            string
+}
+
+// Timeouts holds the HTTP client timeouts. Connect limits
+// establishing the connection and Response limits waiting
+// for the response headers once the request is sent.
+type Timeouts struct {
+	Connect  time.Duration
+	Response time.Duration
 }
 
 const (
@@ -27,319 +37,10 @@ const (
 	keepAliveTimeout               = time.Second * 300 // 5 minutes
 	maxIdleConnectionsLimit        = 100
 	maxIdleConnectionsPerHostLimit = 100
+	defaultConnectTimeout          = time.Second * 30
+	defaultResponseTimeout         = time.Second * 60
 )
 
-var restyClient *resty.Client
-
-// Initialize the new Resty client
-// as part of init and reuse it
-func init() {
-	restyClient = getNewRestyClient()
-}
-
-// Get makes an HTTP call to given URL and returns Response
-func Get(URL string) (Response, error) {
-	return GetHeadersCustomRetry(URL, nil, -1, -1)
-}
-
-// GetRetry makes an HTTP call to given URL and returns Response
-func GetRetry(URL string) (Response, error) {
-	return GetHeadersCustomRetry(URL, nil, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// GetCustomRetry makes an HTTP call to given URL with custom
-// retry options and returns Response
-func GetCustomRetry(URL string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	return GetHeadersCustomRetry(URL, nil, retryCount, retryWaitSeconds)
-}
-
-// GetHeaders makes an HTTP call to given URL with headers
-// and returns response
-func GetHeaders(URL string, headers map[string]string) (Response, error) {
-	return GetHeadersCustomRetry(URL, headers, -1, -1)
-}
-
-// GetHeadersRetry makes an HTTP call to given URL with headers
-// and returns response. It also retries for failures.
-func GetHeadersRetry(URL string, headers map[string]string) (Response, error) {
-	return GetHeadersCustomRetry(URL, headers, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// GetHeadersCustomRetry makes an HTTP call to given URL with headers
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func GetHeadersCustomRetry(URL string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	resp, err := request.
-		SetHeaders(headers).
-		Get(URL)
-	return processResponse(resp), err
-}
-
-// GetBasicAuth makes an HTTP call to given URL with basic authentication
-// and returns response.
-func GetBasicAuth(URL string, username string, password string) (Response, error) {
-	return GetBasicAuthCustomRetry(URL, username, password, -1, -1)
-}
-
-// GetBasicAuthRetry makes an HTTP call to given URL with basic authentication
-// and returns response. It also retries for failures.
-func GetBasicAuthRetry(URL string, username string, password string) (Response, error) {
-	return GetBasicAuthCustomRetry(URL, username, password, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// GetBasicAuthCustomRetry makes an HTTP call to given URL with basic authentication
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func GetBasicAuthCustomRetry(URL string, username string, password string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	resp, err := request.
-		SetBasicAuth(username, password).
-		Get(URL)
-	return processResponse(resp), err
-}
-
-// GetCustomAuth makes an HTTP call to given URL with custom authentication
-// and returns response.
-func GetCustomAuth(URL string, token string) (Response, error) {
-	return GetCustomAuthCustomRetry(URL, token, -1, -1)
-}
-
-// GetCustomAuthRetry makes an HTTP call to given URL with custom authentication
-// and returns response. It also retries for failures.
-func GetCustomAuthRetry(URL string, token string) (Response, error) {
-	return GetCustomAuthCustomRetry(URL, token, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// GetCustomAuthCustomRetry makes an HTTP call to given URL with custom authentication
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func GetCustomAuthCustomRetry(URL string, token string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	resp, err := request.
-		SetAuthToken(token).
-		Get(URL)
-	return processResponse(resp), err
-}
-
-// GetHeadersCustomAuth makes an HTTP call to given URL with headers and custom auth
-// and returns response.
-func GetHeadersCustomAuth(URL string, headers map[string]string, token string) (Response, error) {
-	return GetHeadersCustomAuthCustomRetry(URL, headers, -1, -1, token)
-}
-
-// GetHeadersCustomAuthRetry makes an HTTP call to given URL with headers and custom auth
-// and returns response. It also retries for failures.
-func GetHeadersCustomAuthRetry(URL string, headers map[string]string, token string) (Response, error) {
-	return GetHeadersCustomAuthCustomRetry(URL, headers, defaultRetryCount, defaultRetryWaitSeconds, token)
-}
-
-// GetHeadersCustomAuthCustomRetry makes an HTTP call to given URL with headers and custom auth
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func GetHeadersCustomAuthCustomRetry(URL string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration, token string) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	resp, err := request.
-		SetAuthToken(token).
-		SetHeaders(headers).
-		Get(URL)
-	return processResponse(resp), err
-}
-
-// Post makes an HTTP call to given URL and returns response
-func Post(URL string, requestBody string) (Response, error) {
-	return PostHeadersCustomRetry(URL, requestBody, nil, -1, -1)
-}
-
-// PostHeaders makes an HTTP call to given URL with headers
-// and returns response
-func PostHeaders(URL string, requestBody string, headers map[string]string) (Response, error) {
-	return PostHeadersCustomRetry(URL, requestBody, headers, -1, -1)
-}
-
-// PostHeadersRetry makes an HTTP call to given URL with headers
-// and returns response. It also retries for failures.
-func PostHeadersRetry(URL string, requestBody string, headers map[string]string) (Response, error) {
-	return PostHeadersCustomRetry(URL, requestBody, headers, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PostHeadersCustomRetry makes an HTTP call to given URL with headers
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PostHeadersCustomRetry(URL string, requestBody string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	if requestBody != "" {
-		request.
-			SetBody(requestBody)
-	}
-	resp, err := request.
-		SetHeaders(headers).
-		Post(URL)
-	return processResponse(resp), err
-}
-
-// PostHeadersFormDataRetry makes an HTTP call to given URL with form data,
-// headers and returns response. It also retries for failures.
-func PostHeadersFormDataRetry(URL string, formData map[string]string, headers map[string]string) (Response, error) {
-	return PostHeadersFormDataCustomRetry(URL, formData, headers, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PostHeadersFormDataCustomRetry makes an HTTP call to given URL with form data,
-// headers and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PostHeadersFormDataCustomRetry(URL string, formData map[string]string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(-1, -1)
-	if formData != nil {
-		request.
-			SetFormData(formData)
-	}
-	resp, err := request.
-		SetHeaders(headers).
-		Post(URL)
-	return processResponse(resp), err
-}
-
-// PostBasicAuth makes an HTTP call to given URL with basic authentication
-// and returns response.
-func PostBasicAuth(URL string, requestBody string, username string, password string) (Response, error) {
-	return PostBasicAuthCustomRetry(URL, requestBody, username, password, -1, -1)
-}
-
-// PostBasicAuthRetry makes an HTTP call to given URL with basic authentication
-// and returns response. It also retries for failures.
-func PostBasicAuthRetry(URL string, requestBody string, username string, password string) (Response, error) {
-	return PostBasicAuthCustomRetry(URL, requestBody, username, password, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PostBasicAuthCustomRetry makes an HTTP call to given URL with basic authentication
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PostBasicAuthCustomRetry(URL string, requestBody string, username string, password string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	if requestBody != "" {
-		request.
-			SetBody(requestBody)
-	}
-	resp, err := request.
-		SetBasicAuth(username, password).
-		Post(URL)
-	return processResponse(resp), err
-}
-
-// PostCustomAuth makes an HTTP call to given URL with custom authentication
-// and returns response.
-func PostCustomAuth(URL string, requestBody string, token string) (Response, error) {
-	return PostCustomAuthCustomRetry(URL, requestBody, token, -1, -1)
-}
-
-// PostCustomAuthRetry makes an HTTP call to given URL with custom authentication
-// and returns response. It also retries for failures
-func PostCustomAuthRetry(URL string, requestBody string, token string) (Response, error) {
-	return PostCustomAuthCustomRetry(URL, requestBody, token, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PostCustomAuthCustomRetry makes an HTTP call to given URL with custom authentication
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PostCustomAuthCustomRetry(URL string, requestBody string, token string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	if requestBody != "" {
-		request.
-			SetBody(requestBody)
-	}
-	resp, err := request.
-		SetAuthToken(token).
-		Post(URL)
-	return processResponse(resp), err
-}
-
-// Put makes PUT HTTP call to given URL and returns response.
-func Put(URL string, requestBody string) (Response, error) {
-	return PutHeadersCustomRetry(URL, requestBody, nil, -1, -1)
-}
-
-// PutHeaders makes PUT HTTP call to given URL with headers
-// and returns response.
-func PutHeaders(URL string, requestBody string, headers map[string]string) (Response, error) {
-	return PutHeadersCustomRetry(URL, requestBody, headers, -1, -1)
-}
-
-// PutHeadersRetry makes PUT HTTP call to given URL with headers
-// and returns response. It also retries for failures.
-func PutHeadersRetry(URL string, requestBody string, headers map[string]string) (Response, error) {
-	return PutHeadersCustomRetry(URL, requestBody, headers, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PutHeadersCustomRetry makes PUT HTTP call to given URL with headers
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PutHeadersCustomRetry(URL string, requestBody string, headers map[string]string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	if requestBody != "" {
-		request.
-			SetBody(requestBody)
-	}
-
-	resp, err := request.
-		SetHeaders(headers).
-		Put(URL)
-	return processResponse(resp), err
-}
-
-// PutBasicAuth makes an HTTP call to given URL with basic authentication
-// and returns response.
-func PutBasicAuth(URL string, requestBody string, username string, password string) (Response, error) {
-	return PutBasicAuthCustomRetry(URL, requestBody, username, password, -1, -1)
-}
-
-// PutBasicAuthRetry makes an HTTP call to given URL with basic authentication
-// and returns response. It also retries for failures.
-func PutBasicAuthRetry(URL string, requestBody string, username string, password string) (Response, error) {
-	return PutBasicAuthCustomRetry(URL, requestBody, username, password, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PutBasicAuthCustomRetry makes an HTTP call to given URL with basic authentication
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PutBasicAuthCustomRetry(URL string, requestBody string, username string, password string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	if requestBody != "" {
-		request.
-			SetBody(requestBody)
-	}
-	resp, err := request.
-		SetBasicAuth(username, password).
-		Put(URL)
-	return processResponse(resp), err
-}
-
-// PutCustomAuth makes an HTTP call to given URL with custom authentication
-// and returns response.
-func PutCustomAuth(URL string, requestBody string, token string) (Response, error) {
-	return PutCustomAuthCustomRetry(URL, requestBody, token, -1, -1)
-}
-
-// PutCustomAuthRetry makes an HTTP call to given URL with custom authentication
-// and returns response. It also retries for failures.
-func PutCustomAuthRetry(URL string, requestBody string, token string) (Response, error) {
-	return PutCustomAuthCustomRetry(URL, requestBody, token, defaultRetryCount, defaultRetryWaitSeconds)
-}
-
-// PutCustomAuthCustomRetry makes an HTTP call to given URL with custom authentication
-// and returns response. It also retries for failures with given retry
-// count and wait seconds.
-func PutCustomAuthCustomRetry(URL string, requestBody string, token string, retryCount int, retryWaitSeconds time.Duration) (Response, error) {
-	request := getNewRestyRequest(retryCount, retryWaitSeconds)
-	if requestBody != "" {
-		request.
-			SetBody(requestBody)
-	}
-	resp, err := request.
-		SetAuthToken(token).
-		Put(URL)
-	return processResponse(resp), err
-}
-
 // ParseResponse reads given Response body
 // and return its string type value
 func ParseResponse(response *http.Response) (string, error) {
@@ -354,15 +55,18 @@ func ParseResponse(response *http.Response) (string, error) {
 }
 
 func processResponse(resp *resty.Response) Response {
-	return Response{resp.StatusCode(), resp.Status(), resp.String(), resp.Header()}
+	response := Response{resp.StatusCode(), resp.Status(), resp.String(), resp.Header(), "", ""}
+	if resp.Request != nil {
+		response.Method = resp.Request.Method
+		response.URL = resp.Request.URL
+	}
+	return response
 }
 
-func getNewRestyRequest(retryCount int, retryWaitSeconds time.Duration) *resty.Request {
-	// If restyClient is  not initialized
-	// already, assign a new one
-	if restyClient == nil {
-		restyClient = getNewRestyClient()
-	}
+func getNewRestyClient(transport http.RoundTripper, retryCount int, retryWaitSeconds time.Duration) *resty.Client {
+	// Create new resty client
+	restyClient := resty.New()
+	restyClient.SetTransport(transport)
 	if retryCount > 0 {
 		restyClient.
 			SetRetryCount(retryCount)
@@ -371,26 +75,46 @@ func getNewRestyRequest(retryCount int, retryWaitSeconds time.Duration) *resty.R
 		restyClient.
 			SetRetryWaitTime(retryWaitSeconds)
 	}
-	return restyClient.R()
-}
-
-func getNewRestyClient() *resty.Client {
-	// Create new resty client
-	restyClient := resty.New()
 
-	// Set limits to connections so that
-	// connections are not blocked
-	customTransport := &http.Transport{
-		//TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // Uncomment to disable SSL check
-		Dial: (&net.Dialer{
-			KeepAlive: keepAliveTimeout,
-		}).Dial,
-		MaxIdleConns:        maxIdleConnectionsLimit,
-		MaxIdleConnsPerHost: maxIdleConnectionsPerHostLimit}
-	restyClient.SetTransport(customTransport)
+	restyClient.
+		SetRetryMaxWaitTime(maxRetryWait).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(retryCondition)
 
 	restyClient.
 		SetRedirectPolicy(resty.
 			FlexibleRedirectPolicy(10))
 	return restyClient
 }
+
+func newTransport(timeouts Timeouts, tlsOptions TLSOptions, proxyOptions ProxyOptions) (*http.Transport, error) {
+	if timeouts.Connect <= 0 {
+		timeouts.Connect = defaultConnectTimeout
+	}
+	if timeouts.Response <= 0 {
+		timeouts.Response = defaultResponseTimeout
+	}
+
+	tlsConfig, err := newTLSConfig(tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+	proxyFunc, err := newProxyFunc(proxyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set limits to connections so that
+	// connections are not blocked
+	return &http.Transport{
+		TLSClientConfig: tlsConfig,
+		Proxy:           proxyFunc,
+		DialContext: (&net.Dialer{
+			Timeout:   timeouts.Connect,
+			KeepAlive: keepAliveTimeout,
+		}).DialContext,
+		TLSHandshakeTimeout:   timeouts.Connect,
+		ResponseHeaderTimeout: timeouts.Response,
+		MaxIdleConns:          maxIdleConnectionsLimit,
+		MaxIdleConnsPerHost:   maxIdleConnectionsPerHostLimit}, nil
+}
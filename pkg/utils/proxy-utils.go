@@ -0,0 +1,65 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyOptions holds the proxy configuration. Empty proxies fall
+// back to HTTP_PROXY and HTTPS_PROXY environment variables, while
+// NoProxy is added to NO_PROXY environment variable. Credentials
+// can be part of the proxy URLs, or given separately with Username
+// and Password.
+type ProxyOptions struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	Username   string
+	Password   string
+}
+
+// newProxyFunc creates proxy selector for the transport
+func newProxyFunc(options ProxyOptions) (func(*http.Request) (*url.URL, error), error) {
+	config := httpproxy.FromEnvironment()
+	if options.HTTPProxy != "" {
+		config.HTTPProxy = options.HTTPProxy
+	}
+	if options.HTTPSProxy != "" {
+		config.HTTPSProxy = options.HTTPSProxy
+	}
+	// Keep the exclusions of the environment as well
+	if options.NoProxy != "" && config.NoProxy != "" {
+		config.NoProxy = config.NoProxy + "," + options.NoProxy
+	} else if options.NoProxy != "" {
+		config.NoProxy = options.NoProxy
+	}
+
+	// Fail early for invalid proxy URLs
+	for _, proxy := range []string{config.HTTPProxy, config.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		if _, err := url.Parse(proxy); err != nil {
+			return nil, fmt.Errorf("Invalid proxy %s. Error : %w", proxy, err)
+		}
+	}
+
+	proxyFunc := config.ProxyFunc()
+	return func(request *http.Request) (*url.URL, error) {
+		proxyURL, err := proxyFunc(request.URL)
+		if err != nil || proxyURL == nil || options.Username == "" {
+			return proxyURL, err
+		}
+
+		// Set credentials on a copy, as the proxy URLs are reused
+		proxyURLWithAuth := *proxyURL
+		proxyURLWithAuth.User = url.UserPassword(options.Username, options.Password)
+		return &proxyURLWithAuth, nil
+	}, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+// Maximum wait between retries, including
+// the wait asked with Retry-After header
+const maxRetryWait = time.Second * 60
+
+// retryCondition retries the requests which are safe to retry.
+// Idempotent requests are retried on connection errors, 429 and
+// 5xx. Non-idempotent requests are retried only when they never
+// reached the server, or were rejected with 429 before processing.
+func retryCondition(resp *resty.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if IsNotSent(err) {
+			return true
+		}
+		return resp != nil && resp.Request != nil && isIdempotent(resp.Request.Method)
+	}
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+
+	statusCode := resp.StatusCode()
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return statusCode >= 500 && isIdempotent(resp.Request.Method)
+}
+
+// retryAfter waits as asked by the server with Retry-After header,
+// otherwise falls back to the exponential backoff with jitter
+func retryAfter(client *resty.Client, resp *resty.Response) (time.Duration, error) {
+	return ParseRetryAfter(resp.Header()), nil
+}
+
+// isIdempotent tells whether repeating the request
+// cannot cause any additional side effects
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// IsNotSent tells whether the request failed before it
+// reached the server, e.g. while resolving the host or
+// connecting to it. Such requests are safe to retry.
+func IsNotSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// ParseRetryAfter parses Retry-After header value, which
+// is either seconds or an HTTP date. Zero is returned
+// when the header is missing or invalid.
+func ParseRetryAfter(headers http.Header) time.Duration {
+	retryAfter := headers.Get("Retry-After")
+	if retryAfter == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil && time.Until(date) > 0 {
+		return time.Until(date)
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+func newRestyResponse(method string, statusCode int) *resty.Response {
+	return &resty.Response{
+		Request:     &resty.Request{Method: method},
+		RawResponse: &http.Response{StatusCode: statusCode, Header: http.Header{}},
+	}
+}
+
+func TestRetryCondition(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name string
+		resp *resty.Response
+		err  error
+		want bool
+	}{
+		{"GET succeeded", newRestyResponse(http.MethodGet, http.StatusOK), nil, false},
+		{"GET 404", newRestyResponse(http.MethodGet, http.StatusNotFound), nil, false},
+		{"GET 500", newRestyResponse(http.MethodGet, http.StatusInternalServerError), nil, true},
+		{"GET 503", newRestyResponse(http.MethodGet, http.StatusServiceUnavailable), nil, true},
+		{"PUT 502", newRestyResponse(http.MethodPut, http.StatusBadGateway), nil, true},
+		{"POST 500", newRestyResponse(http.MethodPost, http.StatusInternalServerError), nil, false},
+		{"POST 429", newRestyResponse(http.MethodPost, http.StatusTooManyRequests), nil, true},
+		{"GET 429", newRestyResponse(http.MethodGet, http.StatusTooManyRequests), nil, true},
+		{"POST not sent", newRestyResponse(http.MethodPost, 0), dialErr, true},
+		{"POST DNS error", newRestyResponse(http.MethodPost, 0), &net.DNSError{Err: "no such host"}, true},
+		{"POST reset after sent", newRestyResponse(http.MethodPost, 0), readErr, false},
+		{"GET reset after sent", newRestyResponse(http.MethodGet, 0), readErr, true},
+		{"GET canceled", newRestyResponse(http.MethodGet, 0), context.Canceled, false},
+		{"GET deadline exceeded", newRestyResponse(http.MethodGet, 0), fmt.Errorf("wrapped: %w", context.DeadlineExceeded), false},
+		{"no response", nil, nil, false},
+		{"no response with error", nil, readErr, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := retryCondition(test.resp, test.err); got != test.want {
+				t.Errorf("retryCondition() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsNotSent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dial error", &net.OpError{Op: "dial", Err: errors.New("refused")}, true},
+		{"wrapped dial error", fmt.Errorf("post: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), true},
+		{"DNS error", &net.DNSError{Err: "no such host"}, true},
+		{"read error", &net.OpError{Op: "read", Err: errors.New("reset")}, false},
+		{"other error", errors.New("EOF"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsNotSent(test.err); got != test.want {
+				t.Errorf("IsNotSent() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"missing", "", 0, 0},
+		{"seconds", "7", 7 * time.Second, 7 * time.Second},
+		{"zero seconds", "0", 0, 0},
+		{"negative seconds", "-3", 0, 0},
+		{"invalid", "soon", 0, 0},
+		{"future date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 55 * time.Second, time.Minute},
+		{"past date", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := http.Header{}
+			if test.value != "" {
+				headers.Set("Retry-After", test.value)
+			}
+			got := ParseRetryAfter(headers)
+			if got < test.min || got > test.max {
+				t.Errorf("ParseRetryAfter(%q) = %v, want between %v and %v", test.value, got, test.min, test.max)
+			}
+		})
+	}
+}
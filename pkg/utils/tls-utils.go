@@ -0,0 +1,58 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// TLSOptions holds the TLS configuration. Certificates
+// and keys are PEM encoded.
+type TLSOptions struct {
+	// CACert is trusted in addition to the system CAs
+	CACert     string
+	ClientCert string
+	ClientKey  string
+	// Insecure skips verification of server certificate
+	Insecure bool
+}
+
+// newTLSConfig creates TLS config for given options.
+// Nil config is returned when no option is set, so
+// that the transport defaults are used.
+func newTLSConfig(options TLSOptions) (*tls.Config, error) {
+	if options == (TLSOptions{}) {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.Insecure}
+
+	// Trust given CAs in addition to the system ones
+	if options.CACert != "" {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM([]byte(options.CACert)) {
+			return nil, errors.New("No valid PEM encoded certificate found in CA certificate")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	// Client certificate and key go together
+	if options.ClientCert != "" || options.ClientKey != "" {
+		if options.ClientCert == "" || options.ClientKey == "" {
+			return nil, errors.New("Both client certificate and client key must be provided")
+		}
+		clientCert, err := tls.X509KeyPair([]byte(options.ClientCert), []byte(options.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("Error while loading client certificate. Error : %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	return tlsConfig, nil
+}
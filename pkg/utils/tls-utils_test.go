@@ -0,0 +1,97 @@
+// Copyright 2020 program was created by VMware, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCertificate creates PEM encoded self-signed certificate and its key
+func newCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	cert, key := newCertificate(t)
+	otherCert, _ := newCertificate(t)
+
+	tests := []struct {
+		name    string
+		options TLSOptions
+		wantErr string
+	}{
+		{"CA certificate", TLSOptions{CACert: cert}, ""},
+		{"client certificate", TLSOptions{ClientCert: cert, ClientKey: key}, ""},
+		{"insecure", TLSOptions{Insecure: true}, ""},
+		{"invalid CA PEM", TLSOptions{CACert: "not a certificate"}, "No valid PEM encoded certificate"},
+		{"certificate without key", TLSOptions{ClientCert: cert}, "Both client certificate and client key"},
+		{"key without certificate", TLSOptions{ClientKey: key}, "Both client certificate and client key"},
+		{"mismatching key", TLSOptions{ClientCert: otherCert, ClientKey: key}, "Error while loading client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := newTLSConfig(tt.options)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newTLSConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newTLSConfig() error = %v", err)
+			}
+			if config == nil {
+				t.Fatal("newTLSConfig() = nil, want config")
+			}
+			if config.InsecureSkipVerify != tt.options.Insecure {
+				t.Errorf("InsecureSkipVerify = %v, want %v", config.InsecureSkipVerify, tt.options.Insecure)
+			}
+			if (tt.options.CACert != "") != (config.RootCAs != nil) {
+				t.Errorf("RootCAs = %v, want set %v", config.RootCAs, tt.options.CACert != "")
+			}
+			if (tt.options.ClientCert != "") != (len(config.Certificates) == 1) {
+				t.Errorf("Certificates = %d, want set %v", len(config.Certificates), tt.options.ClientCert != "")
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigEmpty(t *testing.T) {
+	config, err := newTLSConfig(TLSOptions{})
+	if err != nil || config != nil {
+		t.Errorf("newTLSConfig() = %v, %v, want nil config for empty options", config, err)
+	}
+}